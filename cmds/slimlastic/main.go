@@ -7,23 +7,32 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/fvosberg/slimlastic"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		generate(os.Args[2:])
+		return
+	}
 	var (
-		outFile           = flag.String("out", "", "output file (default stdout)")
-		pkgName           = flag.String("pkg", "", "package name (default will infer)")
-		client            = flag.String("client", "", "client name (default modelElasticsearchClient)")
-		httpTimeout       = flag.Int("timeout", 1, "timout for requests to elasticsearch")
-		indexDefinition   = flag.String("indexDefinition", "", "path to the elasticsearch index definition")
-		preventCommonCode = flag.Bool("preventCommon", false, "prevent the generation of common code") // TODO parse the package
-		typeName          = flag.String("typeName", "", "custom name for the elasticsearch document type")
+		outFile         = flag.String("out", "", "output file (default stdout)")
+		pkgName         = flag.String("pkg", "", "package name (default will infer)")
+		client          = flag.String("client", "", "client name (default modelElasticsearchClient)")
+		exported        = flag.Bool("exported", false, "export the client and its constructor")
+		legacyMethods   = flag.Bool("legacyMethods", false, "generate methods without context, the context aware methods get the suffix Context")
+		httpTimeout     = flag.Duration("timeout", 5*time.Second, "default timeout for requests to elasticsearch")
+		indexName       = flag.String("index", "", "name of the elasticsearch index (default typeName followed by s)")
+		indexDefinition = flag.String("indexDefinition", "", "path to an elasticsearch index definition, merged into the mapping derived from the model")
+		typeName        = flag.String("typeName", "", "custom name for the elasticsearch document type")
+		idField         = flag.String("idField", "", "name of the field holding the document ID (default field tagged with es:\"id\" or ID)")
 	)
 	flag.Usage = func() {
-		fmt.Println(`slimlastic [flags] model [indexDefinition]`)
+		fmt.Println(`slimlastic [flags] model`)
+		fmt.Println(`slimlastic generate -config slimlastic.yaml`)
 		flag.PrintDefaults()
 	}
 	flag.Parse()
@@ -33,36 +42,77 @@ func main() {
 		flag.Usage()
 		os.Exit(1)
 	}
-	model := args[0]
-	if *indexDefinition == "" {
-		fmt.Fprintln(os.Stderr, "Path to elasticsearch index definition not set")
-		flag.Usage()
+	generator := &slimlastic.ClientGenerator{
+		Name:          *client,
+		Exported:      *exported,
+		LegacyMethods: *legacyMethods,
+		Model:         args[0],
+		PkgName:       *pkgName,
+		TypeName:      *typeName,
+		IDField:       *idField,
+		IndexName:     *indexName,
+	}
+	if *httpTimeout != 0 {
+		generator.SetTimeout(*httpTimeout)
+	}
+	generator.SetIndexDefinitionPath(*indexDefinition)
+	err := generateClient(generator, *outFile, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+}
+
+// generateClient writes the client to outFile and the common code to its own file in
+// the directory of outFile. Without outFile, both are written to stdout, unless the
+// common code is already declared in the package in the working directory
+func generateClient(generator *slimlastic.ClientGenerator, outFile string, stdout io.Writer) error {
 	var buf bytes.Buffer
-	var out io.Writer
-	out = os.Stdout
-	if len(*outFile) > 0 {
+	out := stdout
+	if len(outFile) > 0 {
 		out = &buf
+		generator.OutDir = filepath.Dir(outFile)
 	}
-	generator := slimlastic.ClientGenerator{
-		Name:              *client,
-		Model:             model,
-		PkgName:           *pkgName,
-		PreventCommonCode: *preventCommonCode,
-		TypeName:          *typeName,
+	// the common code is generated to its own file, when writing to a file
+	var err error
+	generator.PreventCommonCode = len(outFile) > 0
+	if !generator.PreventCommonCode {
+		generator.PreventCommonCode, err = generator.CommonCodeDeclared()
+		if err != nil {
+			return fmt.Errorf("Parsing of package failed: %s", err)
+		}
 	}
-	if *httpTimeout != 0 {
-		generator.SetTimeout(time.Duration(*httpTimeout))
+	_, err = generator.WriteTo(out)
+	if err != nil {
+		return fmt.Errorf("Generation of code failed: %s", err)
 	}
-	generator.SetIndexDefinitionPath(*indexDefinition)
-	_, err := generator.WriteTo(out)
+	if len(outFile) == 0 {
+		return nil
+	}
+	err = ioutil.WriteFile(outFile, buf.Bytes(), 0644)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Generation of code failed: %s\n", err)
+		return fmt.Errorf("Writing of code failed: %s", err)
+	}
+	err = generator.WriteCommonFile(filepath.Base(outFile))
+	if err != nil {
+		return fmt.Errorf("Generation of common code failed: %s", err)
+	}
+	return nil
+}
+
+// generate generates all clients of a config file
+func generate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	configPath := fs.String("config", "slimlastic.yaml", "path to the YAML or JSON config file")
+	fs.Parse(args)
+	cfg, err := slimlastic.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Loading config failed: %s\n", err)
 		os.Exit(1)
 	}
-	// create the file
-	if len(*outFile) > 0 {
-		err = ioutil.WriteFile(*outFile, buf.Bytes(), 0777)
+	err = cfg.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Generation of code failed: %s\n", err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fvosberg/slimlastic"
+)
+
+func TestGenerateClientToOtherDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkgName string            // -pkg flag
+		files   map[string]string // existing files of the out directory
+	}{
+		{
+			name:    "empty out directory",
+			pkgName: "store",
+		},
+		{
+			name:  "package inferred from the out directory",
+			files: map[string]string{"doc.go": "package store\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/m\n\ngo 1.18\n")
+			writeFile(t, filepath.Join(dir, "model.go"), "package model\n\ntype Thing struct {\n\tID   string `json:\"id\"`\n\tName string `json:\"name\"`\n}\n")
+			outDir := filepath.Join(dir, "store")
+			err := os.Mkdir(outDir, 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, src := range tt.files {
+				writeFile(t, filepath.Join(outDir, name), src)
+			}
+			outFile := filepath.Join(outDir, "thing_client.go")
+			g := &slimlastic.ClientGenerator{Model: "Thing", Dir: dir, PkgName: tt.pkgName}
+
+			err = generateClient(g, outFile, &bytes.Buffer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, slimlastic.CommonFile)); !os.IsNotExist(err) {
+				t.Errorf("common file written to the model directory")
+			}
+			client := parsePackageName(t, outFile)
+			if client != "store" {
+				t.Errorf("expected package store of the client, got %s", client)
+			}
+			common := parsePackageName(t, filepath.Join(outDir, slimlastic.CommonFile))
+			if common != "store" {
+				t.Errorf("expected package store of the common file, got %s", common)
+			}
+			src, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(src), `"example.com/m"`) || !strings.Contains(string(src), "model.Thing") {
+				t.Errorf("expected the client to import the model package")
+			}
+			info, err := os.Stat(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm()&0111 != 0 {
+				t.Errorf("expected the client not to be executable, got mode %s", info.Mode())
+			}
+		})
+	}
+}
+
+func TestGenerateClientsOfOneModel(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/m\n\ngo 1.18\n")
+	writeFile(t, filepath.Join(dir, "model.go"), "package model\n\ntype Thing struct {\n\tID   string `json:\"id\"`\n\tName string `json:\"name\"`\n}\n")
+	clients := []string{"", "archivedThingStore"}
+	declared := map[string]string{}
+	for n, name := range clients {
+		outFile := filepath.Join(dir, fmt.Sprintf("client%d.go", n))
+		g := &slimlastic.ClientGenerator{Model: "Thing", Dir: dir, Name: name}
+
+		err := generateClient(g, outFile, &bytes.Buffer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), outFile, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range f.Decls {
+			for _, ident := range declaredNames(d) {
+				if prev, ok := declared[ident]; ok {
+					t.Errorf("%s is declared by %s and %s", ident, prev, filepath.Base(outFile))
+				}
+				declared[ident] = filepath.Base(outFile)
+			}
+		}
+	}
+}
+
+// declaredNames returns the names of the package level identifiers of the declaration
+func declaredNames(d ast.Decl) []string {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			return []string{d.Name.Name}
+		}
+	case *ast.GenDecl:
+		var names []string
+		for _, s := range d.Specs {
+			switch s := s.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func writeFile(t *testing.T, path, src string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(src), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func parsePackageName(t *testing.T, path string) string {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Name.Name
+}
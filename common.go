@@ -0,0 +1,161 @@
+package slimlastic
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// CommonFile is the name of the file the common code of all clients in a package is generated to
+const CommonFile = "slimlastic_common.go"
+
+// commonTypes are the declarations shared by all clients of a package
+var commonTypes = []string{"elasticError"}
+
+// CommonCodeDeclared checks whether the package in OutDir already declares the common code
+// The files with the given names are ignored, e.g. the file the client is generated to
+func (g *ClientGenerator) CommonCodeDeclared(ignore ...string) (bool, error) {
+	_, declared, err := parsePackageDir(g.outDir(), ignore)
+	return declared, err
+}
+
+// WriteCommonFile generates the common code to the CommonFile in OutDir. It's
+// generated idempotently, so every client of a package can write it. When the
+// common code is already declared in another file of the package, an existing
+// CommonFile is removed instead. The files with the given names are ignored,
+// e.g. the files the clients are generated to
+func (g *ClientGenerator) WriteCommonFile(ignore ...string) error {
+	path := filepath.Join(g.outDir(), CommonFile)
+	pkgName, declared, err := parsePackageDir(g.outDir(), append(ignore, CommonFile))
+	if err != nil {
+		return err
+	}
+	if declared {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "removing %s failed", path)
+		}
+		return nil
+	}
+	if g.PkgName != "" {
+		pkgName = g.PkgName
+	}
+	if pkgName == "" {
+		return errors.Errorf("package name of %s unknown", g.outDir())
+	}
+	var buf bytes.Buffer
+	_, err = writeCommon(&buf, pkgName)
+	if err != nil {
+		return err
+	}
+	existing, err := ioutil.ReadFile(path)
+	if err == nil && bytes.Equal(existing, buf.Bytes()) {
+		return nil
+	}
+	err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s failed", path)
+	}
+	return nil
+}
+
+func (g *ClientGenerator) dir() string {
+	if g.Dir == "" {
+		return "."
+	}
+	return g.Dir
+}
+
+func (g *ClientGenerator) outDir() string {
+	if g.OutDir == "" {
+		return g.dir()
+	}
+	return g.OutDir
+}
+
+// sameDir checks whether both paths point to the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+func writeCommon(w io.Writer, pkgName string) (int64, error) {
+	tmpl, err := template.New("commonFile").Parse(commonFileTemplate)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing template failed")
+	}
+	_, err = tmpl.Parse(commonTemplate)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing template failed")
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, code{TargetPackage: pkgName})
+	if err != nil {
+		return 0, errors.Wrap(err, "executing template failed")
+	}
+	src, err := formatSource(buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(src)
+	return int64(n), err
+}
+
+// parsePackageDir parses the Go files in dir, except the ignored and test files
+// It returns the package name and whether the common code is declared
+func parsePackageDir(dir string, ignore []string) (string, bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", false, err
+	}
+	var (
+		pkgName  string
+		declared bool
+		fset     = token.NewFileSet()
+	)
+	for _, path := range files {
+		name := filepath.Base(path)
+		if strings.HasSuffix(name, "_test.go") || contains(ignore, name) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", false, errors.Wrapf(err, "parsing %s failed", path)
+		}
+		pkgName = f.Name.Name
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if contains(commonTypes, spec.(*ast.TypeSpec).Name.Name) {
+					declared = true
+				}
+			}
+		}
+	}
+	return pkgName, declared, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package slimlastic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes multiple clients, which are generated in one pass
+// It's read from a YAML or JSON file, like
+//
+//	timeout: 2s
+//	clients:
+//	  - model: Example
+//	    out: example_client.go
+//	  - model: github.com/foo/bar.Baz
+//	    client: bazStore
+//	    index: bazs_v1
+//	    indexDefinition: baz.json
+//	    out: esstore/baz_client.go
+type Config struct {
+	Package       string         `yaml:"package"`       // Name of the package the code is generated for, default will infer
+	Timeout       time.Duration  `yaml:"timeout"`       // Default timeout of all clients
+	Exported      bool           `yaml:"exported"`      // Whether all clients are exported
+	LegacyMethods bool           `yaml:"legacyMethods"` // Whether methods without context are generated for all clients
+	Clients       []ClientConfig `yaml:"clients"`
+
+	dir string // Directory of the config file, relative paths are resolved against it
+}
+
+// ClientConfig describes a single client of the Config
+type ClientConfig struct {
+	Model           string             `yaml:"model"`           // Name of the struct, with the import path if it's not in the package of the output file
+	Name            string             `yaml:"client"`          // Name of the generated client
+	Out             string             `yaml:"out"`             // Path of the generated file
+	Package         string             `yaml:"package"`         // Name of the package, overrides Config.Package
+	IndexName       string             `yaml:"index"`           // Name of the elasticsearch index
+	TypeName        string             `yaml:"typeName"`        // Name of the elasticsearch document type
+	IndexDefinition string             `yaml:"indexDefinition"` // Path to an elasticsearch index definition, merged into the derived mapping
+	IDField         string             `yaml:"idField"`         // Name of the field holding the elasticsearch _id
+	Timeout         time.Duration      `yaml:"timeout"`         // Default timeout of the client, overrides Config.Timeout
+	Exported        bool               `yaml:"exported"`        // Whether the client is exported
+	SearchBoosts    map[string]float64 `yaml:"searchBoosts"`    // Boosts of the fields for Search, keyed by their path
+}
+
+// LoadConfig reads the config from the YAML or JSON file at path
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading config failed")
+	}
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	err = dec.Decode(&c)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding config %s failed", path)
+	}
+	for n, cl := range c.Clients {
+		if cl.Model == "" {
+			return nil, errors.Errorf("client %d in %s has no model", n+1, path)
+		}
+		if cl.Out == "" {
+			return nil, errors.Errorf("client %s in %s has no out file", cl.Model, path)
+		}
+	}
+	c.dir = filepath.Dir(path)
+	return &c, nil
+}
+
+// Generators returns a generator and the path of the output file for every client
+// The common code isn't generated into the files of the clients, see Generate
+func (c *Config) Generators() ([]*ClientGenerator, []string) {
+	var (
+		gens []*ClientGenerator
+		outs []string
+	)
+	for _, cl := range c.Clients {
+		out := c.path(cl.Out)
+		dir := filepath.Dir(out)
+		g := &ClientGenerator{
+			Name:              cl.Name,
+			Exported:          c.Exported || cl.Exported,
+			LegacyMethods:     c.LegacyMethods,
+			Model:             cl.Model,
+			PkgName:           c.Package,
+			PreventCommonCode: true,
+			Dir:               dir,
+			IDField:           cl.IDField,
+			TypeName:          cl.TypeName,
+			IndexName:         cl.IndexName,
+			SearchBoosts:      cl.SearchBoosts,
+		}
+		if cl.Package != "" {
+			g.PkgName = cl.Package
+		}
+		g.SetTimeout(c.Timeout)
+		if cl.Timeout != 0 {
+			g.SetTimeout(cl.Timeout)
+		}
+		if cl.IndexDefinition != "" {
+			g.SetIndexDefinitionPath(c.path(cl.IndexDefinition))
+		}
+		gens = append(gens, g)
+		outs = append(outs, out)
+	}
+	return gens, outs
+}
+
+// Generate generates all clients of the config and writes them to their output files
+// No file is written, if the generation of any client fails
+func (c *Config) Generate() error {
+	gens, outs := c.Generators()
+	srcs := make([][]byte, len(gens))
+	for n, g := range gens {
+		var buf bytes.Buffer
+		_, err := g.WriteTo(&buf)
+		if err != nil {
+			return errors.Wrapf(err, "generating client for %s failed", g.Model)
+		}
+		srcs[n] = buf.Bytes()
+	}
+	for n, out := range outs {
+		err := ioutil.WriteFile(out, srcs[n], 0644)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s failed", out)
+		}
+	}
+	// the common code is generated once for every package
+	files := map[string][]string{}
+	for _, out := range outs {
+		files[filepath.Dir(out)] = append(files[filepath.Dir(out)], filepath.Base(out))
+	}
+	for n, g := range gens {
+		dir := filepath.Dir(outs[n])
+		if files[dir] == nil {
+			continue
+		}
+		err := g.WriteCommonFile(files[dir]...)
+		if err != nil {
+			return errors.Wrapf(err, "generating common code in %s failed", dir)
+		}
+		files[dir] = nil
+	}
+	return nil
+}
+
+func (c *Config) path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.dir, p)
+}
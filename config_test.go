@@ -0,0 +1,208 @@
+package slimlastic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		gens   []ClientGenerator // expected generators, with the output file in OutDir
+		err    string            // expected part of the error
+	}{
+		{
+			name: "defaults",
+			config: `
+clients:
+  - model: Note
+    out: note_client.go
+`,
+			gens: []ClientGenerator{{Model: "Note", Dir: ".", OutDir: "note_client.go", PreventCommonCode: true}},
+		},
+		{
+			name: "settings of all clients",
+			config: `
+package: store
+timeout: 2s
+exported: true
+legacyMethods: true
+clients:
+  - model: Note
+    out: store/note_client.go
+  - model: example.com/m.Tag
+    client: tagStore
+    package: tags
+    timeout: 5s
+    index: tags_v1
+    typeName: tag
+    idField: Key
+    indexDefinition: tag.json
+    searchBoosts:
+      name: 2
+    out: /tmp/tags/tag_client.go
+`,
+			gens: []ClientGenerator{
+				{Model: "Note", Dir: "store", OutDir: "store/note_client.go", PkgName: "store", Exported: true, LegacyMethods: true, PreventCommonCode: true, timeout: 2 * time.Second},
+				{
+					Model: "example.com/m.Tag", Name: "tagStore", Dir: "/tmp/tags", OutDir: "/tmp/tags/tag_client.go", PkgName: "tags", Exported: true, LegacyMethods: true,
+					PreventCommonCode: true, timeout: 5 * time.Second, IndexName: "tags_v1", TypeName: "tag", IDField: "Key", indexDefinitionPath: "tag.json",
+					SearchBoosts: map[string]float64{"name": 2},
+				},
+			},
+		},
+		{
+			name:   "JSON",
+			config: `{"clients": [{"model": "Note", "out": "note_client.go", "exported": true}]}`,
+			gens:   []ClientGenerator{{Model: "Note", Dir: ".", OutDir: "note_client.go", Exported: true, PreventCommonCode: true}},
+		},
+		{
+			name:   "invalid YAML",
+			config: "clients:\n  - model: Note\n   out: note_client.go\n",
+			err:    "decoding config",
+		},
+		{
+			name:   "unknown key",
+			config: "timeout: 2s\nclient:\n  - model: Note\n",
+			err:    "field client not found",
+		},
+		{
+			name:   "unknown key of a client",
+			config: "clients:\n  - model: Note\n    out: note_client.go\n    indexName: notes\n",
+			err:    "field indexName not found",
+		},
+		{
+			name:   "invalid timeout",
+			config: "timeout: soon\n",
+			err:    "decoding config",
+		},
+		{
+			name:   "client without model",
+			config: "clients:\n  - out: note_client.go\n",
+			err:    "client 1 in",
+		},
+		{
+			name:   "client without out file",
+			config: "clients:\n  - model: Note\n",
+			err:    "client Note in",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "slimlastic.yaml")
+			err := os.WriteFile(path, []byte(tt.config), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := LoadConfig(path)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			gens, outs := c.Generators()
+			if len(gens) != len(tt.gens) {
+				t.Fatalf("expected %d generators, got %d", len(tt.gens), len(gens))
+			}
+			for n, want := range tt.gens {
+				// relative paths are resolved against the directory of the config
+				for _, p := range []*string{&want.Dir, &want.OutDir, &want.indexDefinitionPath} {
+					if *p != "" && !filepath.IsAbs(*p) {
+						*p = filepath.Join(dir, *p)
+					}
+				}
+				if outs[n] != want.OutDir {
+					t.Errorf("expected out file %s, got %s", want.OutDir, outs[n])
+				}
+				want.OutDir = ""
+				if got := *gens[n]; !reflect.DeepEqual(got, want) {
+					t.Errorf("expected generator %+v, got %+v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		clients string   // clients of the config
+		files   []string // expected files in lexical order, besides those of the package
+		err     bool
+	}{
+		{
+			name:    "clients in several packages",
+			clients: "  - model: Note\n    out: note_client.go\n  - model: Note\n    client: archivedNoteStore\n    out: note_archive_client.go\n  - model: example.com/m.Note\n    out: store/note_client.go\n    package: store\n",
+			files:   []string{"note_archive_client.go", "note_client.go", CommonFile, "store/note_client.go", "store/" + CommonFile},
+		},
+		{
+			name:    "no file written on failure",
+			clients: "  - model: Note\n    out: note_client.go\n  - model: Missing\n    out: missing_client.go\n",
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, src := range map[string]string{
+				"go.mod":          "module example.com/m\n\ngo 1.18\n",
+				"model.go":        "package model\n\ntype Note struct {\n\tID   string `json:\"id\"`\n\tText string `json:\"text\" es:\"text\"`\n}\n",
+				"slimlastic.yaml": "clients:\n" + tt.clients,
+				"store/doc.go":    "package store\n",
+			} {
+				path := filepath.Join(dir, name)
+				err := os.MkdirAll(filepath.Dir(path), 0755)
+				if err == nil {
+					err = os.WriteFile(path, []byte(src), 0644)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			c, err := LoadConfig(filepath.Join(dir, "slimlastic.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Generate()
+
+			if tt.err != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			var files []string
+			err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, _ := filepath.Rel(dir, path)
+				switch rel {
+				case "go.mod", "model.go", "slimlastic.yaml", filepath.Join("store", "doc.go"):
+					return nil
+				}
+				files = append(files, filepath.ToSlash(rel))
+				if info.Mode().Perm()&0111 != 0 {
+					t.Errorf("expected %s not to be executable, got mode %s", rel, info.Mode())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(files, " ") != strings.Join(tt.files, " ") {
+				t.Errorf("expected files %v, got %v", tt.files, files)
+			}
+		})
+	}
+}
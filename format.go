@@ -0,0 +1,62 @@
+package slimlastic
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// formatSource removes unused imports from the generated source and formats it with go/format
+// Syntax errors are reported with the affected lines of the generated source
+func formatSource(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, syntaxError(src, err)
+	}
+	var unused []string
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid import %s", imp.Path.Value)
+		}
+		if !astutil.UsesImport(f, path) {
+			unused = append(unused, path)
+		}
+	}
+	for _, path := range unused {
+		astutil.DeleteImport(fset, f, path)
+	}
+	var buf bytes.Buffer
+	err = format.Node(&buf, fset, f)
+	if err != nil {
+		return nil, errors.Wrap(err, "formatting generated code failed")
+	}
+	return buf.Bytes(), nil
+}
+
+// syntaxError adds the affected lines of the generated source to the errors of the parser
+func syntaxError(src []byte, err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return errors.Wrap(err, "parsing generated code failed")
+	}
+	lines := strings.Split(string(src), "\n")
+	var b strings.Builder
+	b.WriteString("generated code has syntax errors:")
+	for _, e := range list {
+		fmt.Fprintf(&b, "\n%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+		if e.Pos.Line > 0 && e.Pos.Line <= len(lines) {
+			fmt.Fprintf(&b, "\n\t%d | %s", e.Pos.Line, lines[e.Pos.Line-1])
+		}
+	}
+	return errors.New(b.String())
+}
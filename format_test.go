@@ -0,0 +1,76 @@
+package slimlastic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFormatSource(t *testing.T) {
+	tmpl := template.Must(template.New("client").Parse(`package {{.Pkg}}
+
+import (
+	"fmt"
+	"strings"
+)
+
+// {{.Client}} is a broken client
+type {{.Client}} struct{ name string }
+
+func (c *{{.Client}}) Name() string {
+	{{.Body}}
+}
+`))
+	tests := []struct {
+		name string
+		body string
+		want string   // expected part of the formatted source
+		errs []string // expected parts of the error
+	}{
+		{
+			name: "unused import removed",
+			body: `return strings.ToUpper(c.name)`,
+			want: "import (\n\t\"strings\"\n)",
+		},
+		{
+			name: "missing parenthesis",
+			body: `return strings.ToUpper(c.name`,
+			errs: []string{"generated code has syntax errors:", "13:1: ", "\n\t13 | }"},
+		},
+		{
+			name: "reported line",
+			body: "x := 1 +\n\treturn fmt.Sprint(x",
+			errs: []string{"13:2: ", "\n\t13 | \treturn fmt.Sprint(x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tmpl.Execute(&buf, map[string]string{"Pkg": "store", "Client": "NoteClient", "Body": tt.body})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			src, err := formatSource(buf.Bytes())
+
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(src), tt.want) {
+					t.Errorf("expected source containing %q, got\n%s", tt.want, src)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error, got\n%s", src)
+			}
+			for _, e := range tt.errs {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error containing %q, got\n%s", e, err)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package slimlastic
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
 	"io"
 	"io/ioutil"
+	"path"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -10,16 +16,26 @@ import (
 	"github.com/pkg/errors"
 )
 
+// defaultTimeout is the timeout for requests of the generated client, if none is set with SetTimeout
+const defaultTimeout = 5 * time.Second
+
 // ClientGenerator generates a new slimlastic client for a given struct (called model)
 type ClientGenerator struct {
-	Name              string // Name of the generated client
+	Name              string // Name of the generated client, defaults to the model followed by ElasticsearchClient
+	Exported          bool   // Whether the generated client and its constructor are exported
+	LegacyMethods     bool   // Whether methods without context are generated, the context aware ones get the suffix Context
 	Model             string // Name of the struct this client should handle
 	PkgName           string // Name of the package the code is generated for
-	PreventCommonCode bool
+	PreventCommonCode bool   // Whether the common code is left out, see CommonCodeDeclared and WriteCommonFile
+	Dir               string // Directory of the package the model is loaded from, defaults to the working directory
+	OutDir            string // Directory of the package the code is generated for, defaults to Dir
+	IDField           string // Name of the field holding the elasticsearch _id, defaults to the field tagged with es:"id" or ID
 
-	timeout             time.Duration // Timeout for requests to elasticsearch for the generated client. Can be set with SetTimeout
-	indexDefinitionPath string        // TODO to reader
-	TypeName            string        // Name of the elasticsearch document type, default to lowercase model
+	timeout             time.Duration      // Timeout for requests to elasticsearch for the generated client. Can be set with SetTimeout
+	indexDefinitionPath string             // TODO to reader
+	TypeName            string             // Name of the elasticsearch document type, default to lowercase model
+	IndexName           string             // Name of the elasticsearch index, defaults to the type name followed by s
+	SearchBoosts        map[string]float64 // Boosts of fields for Search, keyed by their path like entity.name, see searchFields
 }
 
 type code struct {
@@ -29,28 +45,67 @@ type code struct {
 	SourcePackage     string
 	TargetPackage     string
 	Imports           []string
-	UppercaseClient   string
+	ImportNames       map[string]string // Names of the imports, which differ from the last element of their path
+	StdImports        []string          // Imports of the standard library, split from Imports before rendering
 	LowercaseClient   string
+	Client            string // Name of the client type, exported or not
+	Constructor       string
+	Timeout           string // Default timeout of the generated client as a Go expression
+	LegacyMethods     bool
+	Ctx               string // Suffix of the context aware methods
+	ModelPrefix       string // Prefix of types and functions, which are exported together with the client
+	DefaultName       bool   // Whether the client has the default name, only such clients declare the deprecated identifiers named after the model
 	IndexName         string
 	TypeName          string
 	IndexDefinition   string
+	SearchFields      []string // Fields of the multi_match query of Search, with their boosts
+	QueryFields       []queryField
+	FieldPaths        []fieldPath
+	IDPath            string // Path of the ID in the documents
+	StoreID           bool   // Whether the ID is added to the documents at IDPath, as it's not in their JSON
 	WithConstructor   bool
 	PreventCommonCode bool
+	Fields            []modelField
+	IDField           string
+	IDType            string
+	IDKind            string
+	IDBits            int // Bit size of integer IDs, see modelID
+	IDComparable      bool
 }
 
 // WriteTo writes the generated code to the given writer
 func (g *ClientGenerator) WriteTo(w io.Writer) (int64, error) {
-	model := g.Model
+	m, err := loadModel(g.Dir, g.Model, g.IDField)
+	if err != nil {
+		return 0, errors.Wrap(err, "loading model failed")
+	}
+	if m.Local && !sameDir(g.dir(), g.outDir()) {
+		// the model is imported by the package the code is generated for
+		m.Local = false
+	}
+	model := m.Name
 	modelWithPrefix := model
-	sourcePackage := g.PkgName
-	if i := strings.LastIndex(model, "."); i > -1 {
-		model = g.Model[i+1:]
-		sourcePackage = g.Model[:i]
-		if i := strings.LastIndex(sourcePackage, "/"); i > -1 {
-			modelWithPrefix = sourcePackage[i+1:] + "." + model
+	pkgName := g.PkgName
+	if pkgName == "" && m.Local {
+		pkgName = m.PkgName
+	}
+	if pkgName == "" {
+		pkgName, _, err = parsePackageDir(g.outDir(), nil)
+		if err != nil {
+			return 0, err
 		}
+		if pkgName == "" {
+			return 0, errors.Errorf("package name of %s unknown", g.outDir())
+		}
+	}
+	if !m.Local {
+		modelWithPrefix = m.PkgName + "." + model
+	}
+	clientName := g.Name
+	if clientName == "" {
+		clientName = model + "ElasticsearchClient"
 	}
-	clientName := model + "ElasticsearchClient" // TODO configurable
+	uppercaseClient := strings.ToUpper(string(clientName[0])) + clientName[1:]
 	typeName := g.TypeName
 	if typeName == "" {
 		typeName = strings.ToLower(model)
@@ -60,33 +115,176 @@ func (g *ClientGenerator) WriteTo(w io.Writer) (int64, error) {
 		Model:             model,
 		ModelWithPrefix:   modelWithPrefix,
 		LowercaseModel:    strings.ToLower(string(model[0])) + model[1:],
-		SourcePackage:     sourcePackage,
-		TargetPackage:     g.PkgName,
-		Imports:           []string{"bytes", "encoding/json", "fmt", "io", "net/http", "strings", "time", "github.com/fvosberg/errtypes", "github.com/pkg/errors"},
-		UppercaseClient:   strings.ToUpper(string(clientName[0])) + clientName[1:],
+		SourcePackage:     m.PkgPath,
+		TargetPackage:     pkgName,
+		Imports:           []string{"context", "io", "net/http", "time", "github.com/fvosberg/slimlastic/runtime", "github.com/pkg/errors"},
 		LowercaseClient:   strings.ToLower(string(clientName[0])) + clientName[1:],
-		IndexName:         typeName + "s",
+		IndexName:         g.IndexName,
 		TypeName:          typeName,
 		WithConstructor:   true,
 		PreventCommonCode: g.PreventCommonCode,
+		Fields:            m.Fields,
+		DefaultName:       clientName == model+"ElasticsearchClient",
+	}
+	if doc.IndexName == "" {
+		doc.IndexName = typeName + "s"
+	}
+	if g.LegacyMethods {
+		doc.LegacyMethods = true
+		doc.Ctx = "Context"
+	}
+	timeout := g.timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
 	}
-	if doc.SourcePackage != doc.TargetPackage {
+	doc.Timeout = durationExpr(timeout)
+	doc.Client = doc.LowercaseClient
+	doc.Constructor = "new" + uppercaseClient
+	doc.ModelPrefix = doc.LowercaseModel
+	if g.Exported {
+		doc.Client = uppercaseClient
+		doc.Constructor = "New" + uppercaseClient
+		doc.ModelPrefix = model
+	}
+	if !m.Local {
 		doc.Imports = append(doc.Imports, doc.SourcePackage)
+		m.importName(m.PkgPath, m.PkgName)
+	}
+	var idImports []string
+	doc.IDField = m.ID.Field
+	doc.IDKind = m.ID.Kind
+	doc.IDBits = m.ID.Bits
+	doc.IDComparable = m.ID.Comparable
+	doc.IDType, idImports = qualifiedType(m, m.ID.Type)
+	doc.Imports = appendImports(doc.Imports, idImports...)
+	if doc.IDKind == idKindInt || doc.IDKind == idKindUint {
+		doc.Imports = appendImports(doc.Imports, "strconv")
+	}
+	var override []byte
+	if g.indexDefinitionPath != "" {
+		override, err = ioutil.ReadFile(g.indexDefinitionPath)
+		if err != nil {
+			return 0, errors.Wrap(err, "reading index definition file failed")
+		}
+	}
+	def, err := indexDefinition(m, typeName, override)
+	if err != nil {
+		return 0, err
 	}
-	indexDef, err := ioutil.ReadFile(g.indexDefinitionPath)
+	doc.SearchFields, err = searchFields(m, def, typeName, g.SearchBoosts)
 	if err != nil {
-		return 0, errors.Wrap(err, "reading index definition file failed")
+		return 0, err
+	}
+	var queryImports []string
+	doc.QueryFields, queryImports = queryFields(m, def, typeName)
+	doc.Imports = appendImports(doc.Imports, queryImports...)
+	doc.FieldPaths = fieldPaths(m, def, typeName)
+	doc.IDPath = idPath(m, doc.QueryFields)
+	if doc.IDPath == "" {
+		// GetPage needs a sortable ID in the documents, as elasticsearch can't sort by _id
+		doc.IDPath = storedIDPath(def, typeName)
+		doc.StoreID = true
+		def = mergeMaps(def, map[string]interface{}{
+			"mappings": map[string]interface{}{
+				typeName: map[string]interface{}{
+					"properties": map[string]interface{}{doc.IDPath: map[string]interface{}{"type": "keyword"}},
+				},
+			},
+		})
+	}
+	indexDef, err := json.MarshalIndent(def, "", "\t")
+	if err != nil {
+		return 0, errors.Wrap(err, "encoding index definition failed")
 	}
 	doc.IndexDefinition = string(indexDef)
 	tmpl, err := template.New("client").Parse(clientTemplate)
 	if err != nil {
 		return 0, errors.Wrap(err, "parsing template failed")
 	}
-	err = tmpl.Execute(w, doc)
+	_, err = tmpl.Parse(commonTemplate)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing template failed")
+	}
+	doc.StdImports, doc.Imports = splitStdImports(doc.Imports)
+	doc.ImportNames = m.ImportNames
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, doc)
 	if err != nil {
 		return 0, errors.Wrap(err, "executing template failed")
 	}
-	return 0, nil // TODO
+	src, err := formatSource(buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(src)
+	return int64(n), err
+}
+
+// durationExpr returns the Go expression for the duration, like 5 * time.Second
+func durationExpr(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// qualifiedType returns the type as it is written in the generated code and the
+// import paths of the packages it refers to
+func qualifiedType(m *modelStruct, t types.Type) (string, []string) {
+	var imports []string
+	str := types.TypeString(t, func(p *types.Package) string {
+		if m.Local && p.Path() == m.PkgPath {
+			return ""
+		}
+		imports = append(imports, p.Path())
+		m.importName(p.Path(), p.Name())
+		return p.Name()
+	})
+	return str, imports
+}
+
+// importName records the name of the imported package, when it differs from the last element of its path
+func (m *modelStruct) importName(importPath, name string) {
+	if path.Base(importPath) != name {
+		m.ImportNames[importPath] = name
+	}
+}
+
+// appendImports appends the import paths, which aren't imported yet
+func appendImports(imports []string, paths ...string) []string {
+	for _, p := range paths {
+		if !contains(imports, p) {
+			imports = append(imports, p)
+		}
+	}
+	return imports
+}
+
+// splitStdImports splits the imports of the standard library from the others, both sorted
+func splitStdImports(imports []string) ([]string, []string) {
+	var std, others []string
+	for _, i := range imports {
+		if strings.Contains(strings.Split(i, "/")[0], ".") {
+			others = append(others, i)
+		} else {
+			std = append(std, i)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	return std, others
 }
 
 // SetTimeout sets the timeout for requests to elasticsearch for the generated client
@@ -94,7 +292,8 @@ func (g *ClientGenerator) SetTimeout(d time.Duration) {
 	g.timeout = d
 }
 
-// SetIndexDefinitionPath sets the path to the elasticsearch index definition JSON
+// SetIndexDefinitionPath sets the path to an elasticsearch index definition JSON
+// It is merged into the index definition derived from the es tags of the model
 func (g *ClientGenerator) SetIndexDefinitionPath(p string) {
 	g.indexDefinitionPath = p
 }
@@ -0,0 +1,142 @@
+package slimlastic
+
+import (
+	"go/types"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// kinds of ID fields, which determine the generated conversion from and to the elasticsearch _id
+const (
+	idKindString = "string" // string or a type based on string
+	idKindInt    = "int"    // signed integer types
+	idKindUint   = "uint"   // unsigned integer types
+	idKindText   = "text"   // types implementing fmt.Stringer and encoding.TextUnmarshaler, like uuid.UUID
+)
+
+// modelID is the field of the model which holds the elasticsearch _id
+type modelID struct {
+	Field      string // Name of the field, fields of embedded structs are promoted
+	Type       types.Type
+	Kind       string // One of the idKind constants
+	Bits       int    // Bit size of integer IDs for strconv, 0 for int and uint
+	Comparable bool   // Whether the zero value of the ID can be detected
+}
+
+// resolveID resolves the ID field of the model. The field is, in this order,
+// the field with the given name, the field tagged with es:"id" or the field named ID
+func resolveID(t *types.Named, name string) (*modelID, error) {
+	if name == "" {
+		name = taggedIDField(t.Underlying().(*types.Struct), map[*types.Struct]bool{})
+	}
+	if name == "" {
+		name = "ID"
+	}
+	v, indirect, ok := lookupField(t, name)
+	if !ok {
+		return nil, errors.Errorf("field %s not found", name)
+	}
+	if indirect {
+		// the generated code would dereference a nil pointer for a zero value of the model
+		return nil, errors.Errorf("field %s is promoted through an embedded pointer, embed the struct by value", name)
+	}
+	if !v.Exported() {
+		return nil, errors.Errorf("field %s is not exported", name)
+	}
+	kind := idKind(v.Type())
+	if kind == "" {
+		return nil, errors.Errorf("type %s of field %s is neither a string, an integer nor implements fmt.Stringer and encoding.TextUnmarshaler", v.Type(), name)
+	}
+	return &modelID{
+		Field:      name,
+		Type:       v.Type(),
+		Kind:       kind,
+		Bits:       idBits(v.Type()),
+		Comparable: types.Comparable(v.Type()),
+	}, nil
+}
+
+// taggedIDField returns the name of the field tagged with es:"id", including embedded structs
+// The visited structs are skipped, as structs can embed themselves through pointers
+func taggedIDField(st *types.Struct, visited map[*types.Struct]bool) string {
+	visited[st] = true
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if parseESTag(reflect.StructTag(st.Tag(i)).Get("es")).ID {
+			return f.Name()
+		}
+		if !f.Embedded() {
+			continue
+		}
+		t := f.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if embedded, ok := t.Underlying().(*types.Struct); ok && !visited[embedded] {
+			if name := taggedIDField(embedded, visited); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func idKind(t types.Type) string {
+	if isStringer(t) && isTextUnmarshaler(t) {
+		return idKindText
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch {
+	case b.Kind() == types.String:
+		return idKindString
+	case b.Info()&types.IsUnsigned != 0:
+		return idKindUint
+	case b.Info()&types.IsInteger != 0:
+		return idKindInt
+	}
+	return ""
+}
+
+// idBits returns the bit size of the integer type, to reject _ids out of its range
+// It's 0 for the types without a fixed size, which strconv parses with the size of int
+func idBits(t types.Type) int {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return 0
+	}
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	case types.Int64, types.Uint64:
+		return 64
+	}
+	return 0
+}
+
+func isStringer(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, "String")
+	f, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := f.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.String])
+}
+
+func isTextUnmarshaler(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, "UnmarshalText")
+	f, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := f.Type().(*types.Signature)
+	return sig.Params().Len() == 1 && sig.Results().Len() == 1
+}
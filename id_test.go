@@ -0,0 +1,112 @@
+package slimlastic
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestResolveID(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		field string // expected ID field, empty if the model has to be rejected
+		bits  int    // expected bit size of the ID
+	}{
+		{
+			name:  "field",
+			src:   "type M struct { ID string }",
+			field: "ID",
+		},
+		{
+			name:  "embedded struct",
+			src:   "type Base struct { ID string }\ntype M struct { Base }",
+			field: "ID",
+		},
+		{
+			name:  "tagged field of embedded struct",
+			src:   "type Base struct { Key int `es:\"id\"` }\ntype M struct { Base }",
+			field: "Key",
+		},
+		{
+			name:  "self embedding struct",
+			src:   "type M struct {\n\t*M\n\tID string\n}",
+			field: "ID",
+		},
+		{
+			name:  "pointer cycle of embedded structs",
+			src:   "type A struct { *B }\ntype B struct { *A }\ntype M struct {\n\tA\n\tID string\n}",
+			field: "ID",
+		},
+		{
+			name:  "int",
+			src:   "type M struct { ID int }",
+			field: "ID",
+		},
+		{
+			name:  "int32",
+			src:   "type M struct { ID int32 }",
+			field: "ID",
+			bits:  32,
+		},
+		{
+			name:  "type based on uint8",
+			src:   "type Key uint8\ntype M struct { ID Key }",
+			field: "ID",
+			bits:  8,
+		},
+		{
+			name:  "uint64",
+			src:   "type M struct { ID uint64 }",
+			field: "ID",
+			bits:  64,
+		},
+		{
+			name: "embedded pointer",
+			src:  "type Base struct { ID string }\ntype M struct { *Base }",
+		},
+		{
+			name: "unexported field",
+			src:  "type M struct { iD string }",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := resolveID(namedType(t, tt.src, "M"), "")
+			if tt.field == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got field %s", id.Field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id.Field != tt.field {
+				t.Errorf("expected field %s, got %s", tt.field, id.Field)
+			}
+			if id.Bits != tt.bits {
+				t.Errorf("expected bit size %d, got %d", tt.bits, id.Bits)
+			}
+		})
+	}
+}
+
+// namedType type-checks the declarations and returns the named type
+func namedType(t *testing.T, decls, name string) *types.Named {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "m.go", "package m\n\n"+decls, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := types.Config{Importer: importer.Default()}
+	pkg, err := cfg.Check("m", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg.Scope().Lookup(name).Type().(*types.Named)
+}
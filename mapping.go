@@ -0,0 +1,268 @@
+package slimlastic
+
+import (
+	"encoding/json"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// esTag is the parsed es struct tag of a field
+// The value id marks the ID field of the model, the first other value is the
+// elasticsearch type of the field, all key=value pairs are added to the mapping
+// of the field, except searchBoost, see searchFields. E.g.
+//
+//	es:"keyword"
+//	es:"text,analyzer=german"
+//	es:"text,searchBoost=2"
+//	es:"date,format=epoch_millis"
+//	es:"id"
+//	es:"-"
+type esTag struct {
+	Type    string
+	ID      bool
+	Skip    bool
+	Options map[string]string
+}
+
+func parseESTag(tag string) esTag {
+	var t esTag
+	if tag == "-" {
+		t.Skip = true
+		return t
+	}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			if part == "id" {
+				t.ID = true
+			} else if t.Type == "" {
+				t.Type = part
+			}
+			continue
+		}
+		if t.Options == nil {
+			t.Options = map[string]string{}
+		}
+		t.Options[k] = v
+	}
+	return t
+}
+
+// indexDefinition derives the index definition of the model from its fields and
+// their es tags. The override is merged into the derived definition, values of
+// the override take precedence
+func indexDefinition(m *modelStruct, typeName string, override []byte) (map[string]interface{}, error) {
+	props, err := fieldsMapping(m.Fields, map[*types.Struct]bool{m.Struct: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "deriving mapping of %s failed", m.Name)
+	}
+	def := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			typeName: map[string]interface{}{
+				"properties": props,
+			},
+		},
+	}
+	if len(override) > 0 {
+		var o map[string]interface{}
+		err = json.Unmarshal(override, &o)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding index definition failed")
+		}
+		def = mergeMaps(def, o)
+	}
+	return def, nil
+}
+
+func fieldsMapping(fields []modelField, seen map[*types.Struct]bool) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for _, f := range fields {
+		tag := parseESTag(f.Tag.Get("es"))
+		if tag.Skip {
+			continue
+		}
+		p, err := typeMapping(f.Type, tag, seen)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s", f.Name)
+		}
+		if p != nil {
+			props[f.JSONName] = p
+		}
+	}
+	return props, nil
+}
+
+// typeMapping returns the mapping for a value of the given type
+// It returns nil, if the type can't be mapped and has no explicit es type
+func typeMapping(t types.Type, tag esTag, seen map[*types.Struct]bool) (map[string]interface{}, error) {
+	t = elemType(t)
+	p := map[string]interface{}{}
+	for k, v := range tag.Options {
+		if k == searchBoostOption {
+			continue
+		}
+		p[k] = optionValue(v)
+	}
+	if tag.Type != "" {
+		p["type"] = tag.Type
+	}
+	if isTime(t) {
+		if tag.Type == "" {
+			p["type"] = "date"
+		}
+		return p, nil
+	}
+	if isMarshaler(t) {
+		// like uuid.UUID, which is encoded as a string instead of its underlying array
+		if tag.Type == "" {
+			p["type"] = "keyword"
+		}
+		return p, nil
+	}
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		if tag.Type != "" && tag.Type != "object" && tag.Type != "nested" {
+			return p, nil
+		}
+		if seen[st] {
+			return nil, errors.Errorf("recursive type %s can't be mapped", t)
+		}
+		seen[st] = true
+		defer delete(seen, st)
+		props, err := fieldsMapping(jsonFields(st), seen)
+		if err != nil {
+			return nil, err
+		}
+		p["properties"] = props
+		return p, nil
+	}
+	if tag.Type != "" {
+		return p, nil
+	}
+	typ := basicMapping(t)
+	if typ == "" {
+		return nil, nil
+	}
+	p["type"] = typ
+	return p, nil
+}
+
+// elemType dereferences pointers and returns the element type of slices and arrays
+// elasticsearch has no dedicated array type, every field can hold multiple values
+// Types encoding themselves to JSON are returned as they are, see isMarshaler
+func elemType(t types.Type) types.Type {
+	for {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+			continue
+		}
+		if isMarshaler(t) {
+			return t
+		}
+		switch u := t.Underlying().(type) {
+		case *types.Slice:
+			if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+				return t
+			}
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func isTime(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := n.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time"
+}
+
+// isMarshaler returns whether the type implements encoding.TextMarshaler or json.Marshaler, also by a pointer receiver
+func isMarshaler(t types.Type) bool {
+	for _, name := range []string{"MarshalText", "MarshalJSON"} {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, name)
+		f, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := f.Type().(*types.Signature)
+		if sig.Params().Len() == 0 && sig.Results().Len() == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+func basicMapping(t types.Type) string {
+	if s, ok := t.Underlying().(*types.Slice); ok {
+		if b, ok := s.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "binary"
+		}
+		return ""
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch b.Kind() {
+	case types.String:
+		return "keyword"
+	case types.Bool:
+		return "boolean"
+	case types.Int8, types.Uint8:
+		return "byte"
+	case types.Int16, types.Uint16:
+		return "short"
+	case types.Int32, types.Uint32:
+		return "integer"
+	case types.Int, types.Int64, types.Uint, types.Uint64:
+		return "long"
+	case types.Float32:
+		return "float"
+	case types.Float64:
+		return "double"
+	}
+	return ""
+}
+
+func optionValue(v string) interface{} {
+	if v == "true" || v == "false" {
+		return v == "true"
+	}
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	return v
+}
+
+// mergeMaps merges src into dst recursively, values of src take precedence
+func mergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dst[k] = mergeMaps(dstMap, srcMap)
+	}
+	return dst
+}
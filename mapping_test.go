@@ -0,0 +1,80 @@
+package slimlastic
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIndexDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		override string
+		want     string // expected properties of the mapping, empty if the model has to be rejected
+	}{
+		{
+			name: "basic types",
+			src:  "type M struct { S string; B bool; I8 int8; I int; F float64; Bytes []byte; Strings []string; P *int; C chan int }",
+			want: `{"B":{"type":"boolean"},"Bytes":{"type":"binary"},"F":{"type":"double"},"I":{"type":"long"},"I8":{"type":"byte"},"P":{"type":"long"},"S":{"type":"keyword"},"Strings":{"type":"keyword"}}`,
+		},
+		{
+			name: "time",
+			src:  "import \"time\"\ntype M struct { T time.Time; P *time.Time }",
+			want: `{"P":{"type":"date"},"T":{"type":"date"}}`,
+		},
+		{
+			name: "text marshaler array",
+			src:  "type UUID [16]byte\nfunc (u UUID) MarshalText() ([]byte, error) { return nil, nil }\ntype M struct { ID UUID; IDs []UUID; P *UUID }",
+			want: `{"ID":{"type":"keyword"},"IDs":{"type":"keyword"},"P":{"type":"keyword"}}`,
+		},
+		{
+			name: "json marshaler struct with pointer receiver",
+			src:  "type Money struct { Cents int }\nfunc (m *Money) MarshalJSON() ([]byte, error) { return nil, nil }\ntype M struct { Price Money }",
+			want: `{"Price":{"type":"keyword"}}`,
+		},
+		{
+			name: "es tags",
+			src:  "type UUID [16]byte\nfunc (u UUID) MarshalText() ([]byte, error) { return nil, nil }\ntype M struct { Title string `es:\"text,analyzer=german,searchBoost=2\"`; Ref UUID `es:\"text\"`; Skipped string `es:\"-\"`; Count int `json:\"count\" es:\"integer,index=false\"` }",
+			want: `{"Ref":{"type":"text"},"Title":{"analyzer":"german","type":"text"},"count":{"index":false,"type":"integer"}}`,
+		},
+		{
+			name: "structs",
+			src:  "type Entity struct { ID string `json:\"id\"` }\ntype M struct { Entity Entity `json:\"entity\"`; Items []Entity `json:\"items\" es:\"nested\"`; Raw Entity `es:\"object,enabled=false\"` }",
+			want: `{"Raw":{"enabled":false,"properties":{"id":{"type":"keyword"}},"type":"object"},"entity":{"properties":{"id":{"type":"keyword"}}},"items":{"properties":{"id":{"type":"keyword"}},"type":"nested"}}`,
+		},
+		{
+			name:     "override",
+			src:      "type M struct { Title string; Count int }",
+			override: `{"settings":{"number_of_shards":1},"mappings":{"doc":{"properties":{"Title":{"type":"text"}}}}}`,
+			want:     `{"Count":{"type":"long"},"Title":{"type":"text"}}`,
+		},
+		{
+			name: "recursive type",
+			src:  "type M struct { Children []M }",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, tt.src)
+
+			def, err := indexDefinition(m, "doc", []byte(tt.override))
+
+			if tt.want == "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			props, err := json.Marshal(def["mappings"].(map[string]interface{})["doc"].(map[string]interface{})["properties"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(props) != tt.want {
+				t.Errorf("expected properties\n%s\ngot\n%s", tt.want, props)
+			}
+		})
+	}
+}
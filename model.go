@@ -0,0 +1,240 @@
+package slimlastic
+
+import (
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// modelStruct is the type information of the struct a client is generated for
+type modelStruct struct {
+	Name    string // Name of the struct
+	PkgPath string // Import path of the package declaring the struct
+	PkgName string // Name of the package declaring the struct
+	Local   bool   // Whether the struct is declared in the package the code is generated for
+	Type    *types.Named
+	Struct  *types.Struct
+	Fields  []modelField
+	ID      *modelID
+	// Names of the packages referred to by the generated code, which differ from the last element of their import path
+	ImportNames map[string]string
+}
+
+// modelField is a field of the model, as it is encoded to JSON
+type modelField struct {
+	Name     string // Name of the field in Go, fields of embedded structs are promoted
+	JSONName string // Name of the field in the JSON document
+	Type     types.Type
+	Tag      reflect.StructTag
+}
+
+// loadModel loads the package of the given model and resolves the struct
+// The model is either the name of a struct in the package in dir or an
+// import path followed by the name of the struct, like github.com/foo/bar.Baz
+// The ID field is resolved as described in resolveID
+func loadModel(dir, model, idField string) (*modelStruct, error) {
+	pattern := "."
+	name := model
+	local := true
+	if i := strings.LastIndex(model, "."); i > -1 {
+		pattern = model[:i]
+		name = model[i+1:]
+		local = false
+	}
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading package %q failed", pattern)
+	}
+	if len(pkgs) != 1 {
+		return nil, errors.Errorf("expected exactly one package for %q, but found %d", pattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+	// errors in the package are tolerated as long as the model can be resolved,
+	// because a previously generated client might not compile anymore
+	if pkg.Types == nil {
+		return nil, errors.Errorf("loading package %q failed: %s", pattern, pkg.Errors)
+	}
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		if len(pkg.Errors) > 0 {
+			return nil, errors.Errorf("type %s not found in package %s: %s", name, pkg.PkgPath, pkg.Errors[0])
+		}
+		return nil, errors.Errorf("type %s not found in package %s", name, pkg.PkgPath)
+	}
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, errors.Errorf("%s.%s is not a type", pkg.PkgPath, name)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, errors.Errorf("%s.%s is not a named type", pkg.PkgPath, name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, errors.Errorf("%s.%s is not a struct, but %s", pkg.PkgPath, name, named.Underlying())
+	}
+	m := &modelStruct{
+		Name:    name,
+		PkgPath: pkg.PkgPath,
+		PkgName: pkg.Name,
+		Local:   local,
+		Type:    named,
+		Struct:  st,
+		Fields:  jsonFields(st),
+
+		ImportNames: map[string]string{},
+	}
+	m.ID, err = resolveID(named, idField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s.%s has no usable ID field", pkg.PkgPath, name)
+	}
+	return m, nil
+}
+
+// lookupField looks up the field with the given name, including promoted fields of embedded structs
+// indirect reports whether the field is promoted through an embedded pointer
+func lookupField(t *types.Named, name string) (v *types.Var, indirect bool, ok bool) {
+	obj, _, indirect := types.LookupFieldOrMethod(t, false, t.Obj().Pkg(), name)
+	v, ok = obj.(*types.Var)
+	if !ok || !v.IsField() {
+		return nil, false, false
+	}
+	return v, indirect, true
+}
+
+// jsonCandidate is a field, which is encoded by encoding/json, unless another one with the same JSON name dominates it
+type jsonCandidate struct {
+	modelField
+	index  []int    // Indices of the field and the embedded structs containing it
+	path   []string // Names of the embedded structs containing the field
+	tagged bool     // Whether the JSON name is set by a tag
+}
+
+// dominantField returns the field with the JSON name, which is encoded by encoding/json: the
+// least nested one, and of those the tagged one. It's ambiguous, when there are more of them
+func dominantField(rivals []jsonCandidate) (jsonCandidate, bool) {
+	depth := len(rivals[0].index)
+	for _, c := range rivals {
+		if len(c.index) < depth {
+			depth = len(c.index)
+		}
+	}
+	var shallowest, tagged []jsonCandidate
+	for _, c := range rivals {
+		if len(c.index) != depth {
+			continue
+		}
+		shallowest = append(shallowest, c)
+		if c.tagged {
+			tagged = append(tagged, c)
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return jsonCandidate{}, false
+}
+
+// jsonFields returns the fields of the struct like encoding/json sees them
+// Fields of embedded structs without a JSON name are promoted. Of the fields with
+// the same JSON name, the least nested one wins, then the tagged one. When that's
+// still ambiguous, none of them is encoded. Promoted fields, whose Go name is taken
+// by a less nested field, are named after the embedded structs, like BaseName
+func jsonFields(st *types.Struct) []modelField {
+	type embedded struct {
+		st    *types.Struct
+		index []int
+		path  []string
+	}
+	var (
+		candidates []jsonCandidate
+		current    = []embedded{{st: st}}
+		visited    = map[*types.Struct]bool{}
+	)
+	for len(current) > 0 {
+		var next []embedded
+		for _, e := range current {
+			visited[e.st] = true
+		}
+		for _, e := range current {
+			for i := 0; i < e.st.NumFields(); i++ {
+				f := e.st.Field(i)
+				tag := reflect.StructTag(e.st.Tag(i))
+				jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+				if jsonName == "-" {
+					continue
+				}
+				index := append(append([]int{}, e.index...), i)
+				if f.Embedded() && jsonName == "" {
+					t := f.Type()
+					ptr, isPtr := t.(*types.Pointer)
+					if isPtr {
+						t = ptr.Elem()
+					}
+					if sub, ok := t.Underlying().(*types.Struct); ok {
+						// encoding/json can't allocate embedded pointers to unexported structs
+						if (!isPtr || f.Exported()) && !visited[sub] {
+							next = append(next, embedded{st: sub, index: index, path: append(append([]string{}, e.path...), f.Name())})
+						}
+						continue
+					}
+				}
+				if !f.Exported() {
+					continue
+				}
+				tagged := jsonName != ""
+				if !tagged {
+					jsonName = f.Name()
+				}
+				candidates = append(candidates, jsonCandidate{
+					modelField: modelField{Name: f.Name(), JSONName: jsonName, Type: f.Type(), Tag: tag},
+					index:      index,
+					path:       e.path,
+					tagged:     tagged,
+				})
+			}
+		}
+		current = next
+	}
+	byName := map[string][]jsonCandidate{}
+	for _, c := range candidates {
+		byName[c.JSONName] = append(byName[c.JSONName], c)
+	}
+	var dominant []jsonCandidate
+	for _, rivals := range byName {
+		if c, ok := dominantField(rivals); ok {
+			dominant = append(dominant, c)
+		}
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		a, b := dominant[i].index, dominant[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	fields := make([]modelField, len(dominant))
+	for i, c := range dominant {
+		fields[i] = c.modelField
+		for j, o := range dominant {
+			// the Go name refers to the less nested field, this one is named after its path
+			if j != i && o.Name == c.Name && len(o.index) <= len(c.index) && len(c.path) > 0 {
+				fields[i].Name = strings.Join(c.path, "") + c.Name
+				break
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,68 @@
+package slimlastic
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestJSONFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		fields []string // expected fields as GoName:jsonName
+	}{
+		{
+			name:   "tagged and untagged fields",
+			src:    "type M struct { ID string `json:\"id\"`; Name string; skipped string; Ignored int `json:\"-\"` }",
+			fields: []string{"ID:id", "Name:Name"},
+		},
+		{
+			name:   "promoted fields",
+			src:    "type Base struct { ID string `json:\"id\"` }\ntype M struct { Base; Title string `json:\"title\"` }",
+			fields: []string{"ID:id", "Title:title"},
+		},
+		{
+			name:   "embedded struct with JSON name",
+			src:    "type Base struct { ID string `json:\"id\"` }\ntype M struct { Base `json:\"base\"` }",
+			fields: []string{"Base:base"},
+		},
+		{
+			name:   "shadowed by a less nested field",
+			src:    "type Base struct { Name string `json:\"name\"`; Age int `json:\"age\"` }\ntype M struct { Base; Title string `json:\"name\"` }",
+			fields: []string{"Age:age", "Title:name"},
+		},
+		{
+			name:   "tagged field dominates at the same depth",
+			src:    "type A struct { Name string }\ntype B struct { Other string `json:\"Name\"` }\ntype M struct { A; B }",
+			fields: []string{"Other:Name"},
+		},
+		{
+			name:   "ambiguous fields are dropped",
+			src:    "type A struct { Name string `json:\"name\"` }\ntype B struct { Name string `json:\"name\"` }\ntype M struct { A; B; ID string }",
+			fields: []string{"ID:ID"},
+		},
+		{
+			name:   "same Go name with other JSON names",
+			src:    "type Base struct { Name string `json:\"name\"` }\ntype M struct { Base; Name string `json:\"title\"` }",
+			fields: []string{"BaseName:name", "Name:title"},
+		},
+		{
+			name:   "embedded pointer to unexported struct",
+			src:    "type base struct { Name string }\ntype M struct { *base; ID string }",
+			fields: []string{"ID:ID"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := namedType(t, tt.src, "M")
+			var fields []string
+			for _, f := range jsonFields(m.Underlying().(*types.Struct)) {
+				fields = append(fields, f.Name+":"+f.JSONName)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("expected fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
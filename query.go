@@ -0,0 +1,152 @@
+package slimlastic
+
+import (
+	"go/types"
+)
+
+// kinds of query operators generated for a field, depending on its mapping type
+const (
+	queryKindTerm  = "term"  // Equals and In
+	queryKindMatch = "match" // Matches
+	queryKindRange = "range" // Equals, Between, GreaterThan and LessThan
+)
+
+// queryField is a field of the model, for which query operators are generated
+type queryField struct {
+	Method string // Prefix of the operator methods, the Go path of the field like EntityID
+	Path   string // Path of the field in the document, like entity.id
+	Type   string // Go type of the values
+	Kind   string // One of the queryKind constants
+	Nested string // Path of the nested document containing the field, if any
+}
+
+// queryFields returns the fields of the model, which can be queried, as they are mapped in def
+func queryFields(m *modelStruct, def map[string]interface{}, typeName string) ([]queryField, []string) {
+	mappings, _ := def["mappings"].(map[string]interface{})
+	mapping, _ := mappings[typeName].(map[string]interface{})
+	props, _ := mapping["properties"].(map[string]interface{})
+	var (
+		fields   []queryField
+		imports  []string
+		declared = map[string]bool{}
+	)
+	var walk func(mfs []modelField, props map[string]interface{}, method, path, nested string, seen map[*types.Struct]bool)
+	walk = func(mfs []modelField, props map[string]interface{}, method, path, nested string, seen map[*types.Struct]bool) {
+		for _, f := range mfs {
+			prop, ok := props[f.JSONName].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			t := elemType(f.Type)
+			if st, ok := t.Underlying().(*types.Struct); ok && !isMarshaler(t) {
+				sub, ok := prop["properties"].(map[string]interface{})
+				if !ok || seen[st] {
+					continue
+				}
+				n := nested
+				if prop["type"] == "nested" {
+					n = path + f.JSONName
+				}
+				seen[st] = true
+				walk(jsonFields(st), sub, method+f.Name, path+f.JSONName+".", n, seen)
+				delete(seen, st)
+				continue
+			}
+			kind := queryKind(prop["type"])
+			// fields with the same Go path, like Name tagged as title next to a promoted Name, get the methods only once
+			if kind == "" || declared[method+f.Name] {
+				continue
+			}
+			declared[method+f.Name] = true
+			typ, imps := qualifiedType(m, t)
+			imports = append(imports, imps...)
+			fields = append(fields, queryField{
+				Method: method + f.Name,
+				Path:   path + f.JSONName,
+				Type:   typ,
+				Kind:   kind,
+				Nested: nested,
+			})
+		}
+	}
+	walk(m.Fields, props, "", "", "", map[*types.Struct]bool{m.Struct: true})
+	return fields, imports
+}
+
+// fieldPath is a field of the model, for which a path constant is generated
+type fieldPath struct {
+	Method string // Suffix of the constant, the Go path of the field like EntityID
+	Path   string // Path of the field in the document, like entity.id
+	Nested string // Method of the nested document containing the field, if any
+}
+
+// fieldPaths returns the paths of all JSON fields of the model, including those of
+// embedded and nested structs. The mapping is used to find the nested documents
+func fieldPaths(m *modelStruct, def map[string]interface{}, typeName string) []fieldPath {
+	mappings, _ := def["mappings"].(map[string]interface{})
+	mapping, _ := mappings[typeName].(map[string]interface{})
+	props, _ := mapping["properties"].(map[string]interface{})
+	var paths []fieldPath
+	declared := map[string]bool{}
+	var walk func(mfs []modelField, props map[string]interface{}, method, path, nested string, seen map[*types.Struct]bool)
+	walk = func(mfs []modelField, props map[string]interface{}, method, path, nested string, seen map[*types.Struct]bool) {
+		for _, f := range mfs {
+			if declared[method+f.Name] {
+				continue
+			}
+			declared[method+f.Name] = true
+			paths = append(paths, fieldPath{Method: method + f.Name, Path: path + f.JSONName, Nested: nested})
+			t := elemType(f.Type)
+			st, ok := t.Underlying().(*types.Struct)
+			if !ok || isMarshaler(t) || seen[st] {
+				continue
+			}
+			prop, _ := props[f.JSONName].(map[string]interface{})
+			sub, _ := prop["properties"].(map[string]interface{})
+			n := nested
+			if prop["type"] == "nested" {
+				n = method + f.Name
+			}
+			seen[st] = true
+			walk(jsonFields(st), sub, method+f.Name, path+f.JSONName+".", n, seen)
+			delete(seen, st)
+		}
+	}
+	walk(m.Fields, props, "", "", "", map[*types.Struct]bool{m.Struct: true})
+	return paths
+}
+
+// idPath returns the path of the ID field in the documents, when it can be sorted by
+func idPath(m *modelStruct, fields []queryField) string {
+	for _, f := range fields {
+		if f.Method == m.ID.Field && f.Nested == "" && f.Kind != queryKindMatch {
+			return f.Path
+		}
+	}
+	return ""
+}
+
+// storedIDPath returns the field the ID is stored at in the documents, when it's not in their
+// JSON. It's the first of id, id_, id__ and so on, which isn't a field of the mapping
+func storedIDPath(def map[string]interface{}, typeName string) string {
+	mappings, _ := def["mappings"].(map[string]interface{})
+	mapping, _ := mappings[typeName].(map[string]interface{})
+	props, _ := mapping["properties"].(map[string]interface{})
+	path := "id"
+	for props[path] != nil {
+		path += "_"
+	}
+	return path
+}
+
+func queryKind(mappingType interface{}) string {
+	switch mappingType {
+	case "keyword", "boolean", "ip":
+		return queryKindTerm
+	case "text":
+		return queryKindMatch
+	case "long", "integer", "short", "byte", "double", "float", "half_float", "scaled_float", "date":
+		return queryKindRange
+	}
+	return ""
+}
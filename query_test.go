@@ -0,0 +1,207 @@
+package slimlastic
+
+import (
+	"fmt"
+	"go/types"
+	"testing"
+)
+
+// testModel type-checks the declarations and returns the model M declared by them
+func testModel(t *testing.T, decls string) *modelStruct {
+	t.Helper()
+	named := namedType(t, decls, "M")
+	st := named.Underlying().(*types.Struct)
+	return &modelStruct{
+		Name:        "M",
+		PkgPath:     "m",
+		PkgName:     "m",
+		Local:       true,
+		Type:        named,
+		Struct:      st,
+		Fields:      jsonFields(st),
+		ImportNames: map[string]string{},
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		fields []string // expected fields as Method:Path:Type:Kind:Nested
+	}{
+		{
+			name:   "text marshaler array",
+			src:    "type UUID [16]byte\nfunc (u UUID) MarshalText() ([]byte, error) { return nil, nil }\ntype M struct { ID UUID `json:\"id\"`; Refs []*UUID `json:\"refs\"` }",
+			fields: []string{"ID:id:UUID:term:", "Refs:refs:UUID:term:"},
+		},
+		{
+			name:   "json marshaler struct",
+			src:    "type Money struct { Cents int }\nfunc (m *Money) MarshalJSON() ([]byte, error) { return nil, nil }\ntype M struct { Price Money `json:\"price\"` }",
+			fields: []string{"Price:price:Money:term:"},
+		},
+		{
+			name:   "time",
+			src:    "import \"time\"\ntype M struct { Created time.Time `json:\"created\"` }",
+			fields: []string{"Created:created:time.Time:range:"},
+		},
+		{
+			name:   "kinds by mapping type",
+			src:    "type M struct { Title string `json:\"title\" es:\"text\"`; Tag string `json:\"tag\"`; Count int `json:\"count\"`; Score float64 `json:\"score\"`; Done bool `json:\"done\"`; Raw []byte `json:\"raw\"` }",
+			fields: []string{"Title:title:string:match:", "Tag:tag:string:term:", "Count:count:int:range:", "Score:score:float64:range:", "Done:done:bool:term:"},
+		},
+		{
+			name:   "skipped fields",
+			src:    "type M struct { ID string `json:\"-\"`; Secret string `json:\"secret\" es:\"-\"`; internal string; Name string `json:\"name\"` }",
+			fields: []string{"Name:name:string:term:"},
+		},
+		{
+			name:   "embedded structs",
+			src:    "type Base struct { Created int64 `json:\"created\"`; Name string `json:\"base_name\"` }\ntype M struct { Base; Name string `json:\"name\"` }",
+			fields: []string{"Created:created:int64:range:", "BaseName:base_name:string:term:", "Name:name:string:term:"},
+		},
+		{
+			name:   "object",
+			src:    "type Entity struct { ID string `json:\"id\"`; Title string `json:\"title\" es:\"text\"` }\ntype M struct { Entity Entity `json:\"entity\"` }",
+			fields: []string{"EntityID:entity.id:string:term:", "EntityTitle:entity.title:string:match:"},
+		},
+		{
+			name:   "nested",
+			src:    "type Comment struct { Author string `json:\"author\"`; Likes []int `json:\"likes\"` }\ntype M struct { Comments []Comment `json:\"comments\" es:\"nested\"` }",
+			fields: []string{"CommentsAuthor:comments.author:string:term:comments", "CommentsLikes:comments.likes:int:range:comments"},
+		},
+		{
+			name:   "object in nested",
+			src:    "type User struct { Name string `json:\"name\"` }\ntype Comment struct { User *User `json:\"user\"` }\ntype M struct { Comments []Comment `json:\"comments\" es:\"nested\"` }",
+			fields: []string{"CommentsUserName:comments.user.name:string:term:comments"},
+		},
+		{
+			name:   "boosted field",
+			src:    "type M struct { Title string `json:\"title\" es:\"text,searchBoost=2\"` }",
+			fields: []string{"Title:title:string:match:"},
+		},
+		{
+			name:   "recursive struct",
+			src:    "type Node struct { Name string `json:\"name\"`; Parent *Node `json:\"parent\" es:\"-\"` }\ntype M struct { Node Node `json:\"node\"` }",
+			fields: []string{"NodeName:node.name:string:term:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, tt.src)
+			def, err := indexDefinition(m, "doc", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fields, _ := queryFields(m, def, "doc")
+
+			var got []string
+			for _, f := range fields {
+				got = append(got, fmt.Sprintf("%s:%s:%s:%s:%s", f.Method, f.Path, f.Type, f.Kind, f.Nested))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		paths []string // expected paths as Method:Path:Nested
+	}{
+		{
+			name:  "flat",
+			src:   "type M struct { ID string `json:\"-\"`; Title string `json:\"title\"`; Count int }",
+			paths: []string{"Title:title:", "Count:Count:"},
+		},
+		{
+			name:  "embedded structs",
+			src:   "type Base struct { Created int64 `json:\"created\"`; Name string `json:\"base_name\"` }\ntype M struct { *Base; Name string `json:\"name\"` }",
+			paths: []string{"Created:created:", "BaseName:base_name:", "Name:name:"},
+		},
+		{
+			name:  "object",
+			src:   "type Entity struct { ID string `json:\"id\"` }\ntype M struct { Entity Entity `json:\"entity\"` }",
+			paths: []string{"Entity:entity:", "EntityID:entity.id:"},
+		},
+		{
+			name:  "nested",
+			src:   "type User struct { Name string `json:\"name\"` }\ntype Comment struct { User User `json:\"user\"` }\ntype M struct { Comments []Comment `json:\"comments\" es:\"nested\"` }",
+			paths: []string{"Comments:comments:", "CommentsUser:comments.user:Comments", "CommentsUserName:comments.user.name:Comments"},
+		},
+		{
+			name:  "fields without mapping",
+			src:   "type Blob struct { Data []byte `json:\"data\"` }\ntype M struct { Secret string `json:\"secret\" es:\"-\"`; Blob Blob `json:\"blob\" es:\"-\"` }",
+			paths: []string{"Secret:secret:", "Blob:blob:", "BlobData:blob.data:"},
+		},
+		{
+			name:  "marshaler",
+			src:   "import \"time\"\ntype M struct { Created time.Time `json:\"created\"` }",
+			paths: []string{"Created:created:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, tt.src)
+			def, err := indexDefinition(m, "doc", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			paths := fieldPaths(m, def, "doc")
+
+			var got []string
+			for _, p := range paths {
+				got = append(got, fmt.Sprintf("%s:%s:%s", p.Method, p.Path, p.Nested))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.paths) {
+				t.Errorf("expected paths %v, got %v", tt.paths, got)
+			}
+		})
+	}
+}
+
+func TestIDPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		path   string
+		stored bool // whether the ID has to be stored at the path
+	}{
+		{"ID in the documents", "type M struct { ID string `json:\"key\"` }", "key", false},
+		{"integer ID in the documents", "type M struct { ID int `json:\"id\"` }", "id", false},
+		{"ID not in the documents", "type M struct { ID string `json:\"-\"`; Name string `json:\"name\"` }", "id", true},
+		{"ID mapped as text", "type M struct { ID string `json:\"id\" es:\"text\"` }", "id_", true},
+		{"ID without mapping", "type M struct { ID string `json:\"id\" es:\"-\"` }", "id", true},
+		{"other field named id", "type M struct { ID string `json:\"-\"`; Ref string `json:\"id\"`; Ref2 string `json:\"id_\"` }", "id__", true},
+		{"ID in a nested document", "type Base struct { ID string `json:\"id\"` }\ntype M struct { Key string `json:\"-\" es:\"id\"`; Base []Base `json:\"base\" es:\"nested\"` }", "id", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, tt.src)
+			id, err := resolveID(m.Type, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ID = id
+			def, err := indexDefinition(m, "doc", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields, _ := queryFields(m, def, "doc")
+
+			path := idPath(m, fields)
+			stored := path == ""
+			if stored {
+				path = storedIDPath(def, "doc")
+			}
+
+			if path != tt.path || stored != tt.stored {
+				t.Errorf("expected path %s stored %v, got %s stored %v", tt.path, tt.stored, path, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultKeepIndexVersions is the number of old index versions Reindex keeps for RollbackIndex
+const DefaultKeepIndexVersions = 1
+
+// ErrNoPreviousIndexVersion is returned by RollbackIndex, when there is no older index version
+var ErrNoPreviousIndexVersion = errors.New("no previous index version")
+
+// IndexVersion is a physical index behind the alias of a versioned Client, see SetVersioned
+type IndexVersion struct {
+	Name    string // Name of the index, like examples_v3
+	Version int    // Version of the index, like 3
+	Live    bool   // Whether the alias points to the index
+}
+
+// SetVersioned uses the index name as alias of versioned indices, named like examples_v3
+// EnsureExistingIndex creates the first version, Reindex creates further ones without downtime for reads
+func (c *Client[T]) SetVersioned() {
+	c.versioned = true
+}
+
+// ReindexConfig configures Reindex
+type ReindexConfig[T any] struct {
+	Keep   int                                           // Number of old index versions kept for RollbackIndex
+	Loader func(ctx context.Context, c *Client[T]) error // Fills the new index version instead of copying the live one
+}
+
+// ReindexOption is an option for Reindex
+type ReindexOption[T any] func(*ReindexConfig[T])
+
+// WithKeepIndexVersions keeps n old index versions for RollbackIndex, older ones are deleted
+func WithKeepIndexVersions[T any](n int) ReindexOption[T] {
+	return func(cfg *ReindexConfig[T]) {
+		cfg.Keep = n
+	}
+}
+
+// WithReindexLoader fills the new index version with f instead of copying the
+// documents of the live one. The client passed to f addresses the new index version
+func WithReindexLoader[T any](f func(ctx context.Context, c *Client[T]) error) ReindexOption[T] {
+	return func(cfg *ReindexConfig[T]) {
+		cfg.Loader = f
+	}
+}
+
+// ReindexResult is the result of Reindex
+type ReindexResult struct {
+	Index    string   // Name of the new index version, the alias points to
+	Previous string   // Name of the index the alias pointed to before, empty if there was none
+	Copied   int      // Number of documents copied by the _reindex API
+	Deleted  []string // Names of the old index versions, which have been deleted
+}
+
+// Reindex creates a new index version with the IndexDefinition of the model, copies the
+// documents of the live index into it with the _reindex API or fills it with the loader of
+// WithReindexLoader, and points the alias to it atomically. A concrete index named like the
+// alias, as created by a client which wasn't versioned, is copied and replaced by the alias
+// Documents written in the meantime wouldn't be copied, so writes to the live index are blocked
+// until the alias points to the new version. They fail with ErrUnavailable and have to be
+// retried by the caller. The previous versions stay blocked, until RollbackIndex points the
+// alias to them again
+// For models with StoreID, the copied documents get their _id at the IDPath of the model
+// The client has to be versioned, see SetVersioned. Copying many documents takes longer than
+// the usual timeout, see WithTimeout
+func (c *Client[T]) Reindex(ctx context.Context, opts ...ReindexOption[T]) (_ *ReindexResult, err error) {
+	ctx, finish := c.operation(ctx, "Reindex")
+	defer func() { finish(err) }()
+	if !c.versioned {
+		return nil, errors.Errorf("index %s isn't versioned", c.model.Index)
+	}
+	cfg := ReindexConfig[T]{Keep: DefaultKeepIndexVersions}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	versions, err := c.IndexVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		result = &ReindexResult{}
+		live   []string
+		legacy bool
+		next   = 1
+	)
+	for _, v := range versions {
+		if v.Live {
+			live = append(live, v.Name)
+			result.Previous = v.Name
+		}
+		if v.Version >= next {
+			next = v.Version + 1
+		}
+	}
+	if len(live) == 0 {
+		legacy, err = c.IndexExists(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if legacy {
+			result.Previous = c.model.Index
+		}
+	}
+	result.Index = c.versionName(next)
+	target := c.versionClient(result.Index)
+	err = target.CreateIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked := live
+	if legacy {
+		blocked = []string{c.model.Index}
+	}
+	err = c.blockWrites(ctx, true, blocked...)
+	if err == nil {
+		err = c.fillIndexVersion(ctx, target, result, cfg.Loader)
+	}
+	if err == nil {
+		err = c.pointAlias(ctx, result.Index, live, legacy)
+	}
+	if err != nil {
+		// the alias still points to the live index, the new one is useless
+		_ = c.blockWrites(context.Background(), false, blocked...)
+		_, _ = target.DeleteIndex(context.Background())
+		return nil, err
+	}
+	result.Deleted, err = c.pruneIndexVersions(ctx, versions, result.Previous, cfg.Keep)
+	if err != nil {
+		return result, errors.Wrap(err, "deleting old index versions failed")
+	}
+	return result, nil
+}
+
+// RollbackIndex points the alias to the index version before the live one and removes its write block, see Reindex
+// The live index version is kept, it's deleted by the next Reindex like other old versions
+func (c *Client[T]) RollbackIndex(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "RollbackIndex")
+	defer func() { finish(err) }()
+	versions, err := c.IndexVersions(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		live     []string
+		previous string
+	)
+	for _, v := range versions {
+		if v.Live {
+			live = append(live, v.Name)
+		}
+	}
+	for _, v := range versions {
+		if len(live) > 0 && v.Name == live[0] {
+			break
+		}
+		previous = v.Name
+	}
+	if len(live) == 0 || previous == "" {
+		return ErrNoPreviousIndexVersion
+	}
+	err = c.blockWrites(ctx, false, previous)
+	if err != nil {
+		return err
+	}
+	return c.pointAlias(ctx, previous, live, false)
+}
+
+// IndexVersions returns the index versions of a versioned client sorted by their version, see SetVersioned
+func (c *Client[T]) IndexVersions(ctx context.Context) (_ []IndexVersion, err error) {
+	ctx, finish := c.operation(ctx, "IndexVersions")
+	defer func() { finish(err) }()
+	var response map[string]struct {
+		Aliases map[string]json.RawMessage `json:"aliases"`
+	}
+	err = c.doRequest(idempotent(ctx), "GET", fmt.Sprintf("/%s_v*/_alias", c.model.Index), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	var versions []IndexVersion
+	for name, index := range response {
+		v, err := strconv.Atoi(strings.TrimPrefix(name, c.model.Index+"_v"))
+		if err != nil || c.versionName(v) != name {
+			// another index matching the pattern, like examples_vip_v1
+			continue
+		}
+		_, live := index.Aliases[c.model.Index]
+		versions = append(versions, IndexVersion{Name: name, Version: v, Live: live})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// versionName returns the name of the index version
+func (c *Client[T]) versionName(v int) string {
+	return fmt.Sprintf("%s_v%d", c.model.Index, v)
+}
+
+// versionClient returns a copy of the client, which addresses the index version directly
+func (c *Client[T]) versionClient(name string) *Client[T] {
+	cp := *c
+	cp.versioned = false
+	cp.SetIndex(name)
+	return &cp
+}
+
+// emptyIndexVersion creates a new empty index version and points the alias to it
+func (c *Client[T]) emptyIndexVersion(ctx context.Context) error {
+	_, err := c.Reindex(ctx, WithReindexLoader(func(context.Context, *Client[T]) error {
+		return nil
+	}))
+	return err
+}
+
+// fillIndexVersion fills the new index version with the loader, or copies the documents of the previous index
+func (c *Client[T]) fillIndexVersion(ctx context.Context, target *Client[T], result *ReindexResult, loader func(context.Context, *Client[T]) error) error {
+	if loader != nil {
+		err := loader(ctx, target)
+		if err != nil {
+			return errors.Wrap(err, "loading documents into the new index version failed")
+		}
+		return target.Refresh(ctx)
+	}
+	if result.Previous == "" {
+		return nil
+	}
+	reindex := map[string]interface{}{
+		"source": map[string]interface{}{"index": result.Previous},
+		"dest":   map[string]interface{}{"index": result.Index},
+	}
+	if c.model.StoreID {
+		// documents indexed before the model stored its ID lack it
+		reindex["script"] = map[string]interface{}{
+			"source": "ctx._source[params.path] = ctx._id",
+			"params": map[string]interface{}{"path": c.model.IDPath},
+		}
+	}
+	body, err := json.Marshal(reindex)
+	if err != nil {
+		return err
+	}
+	var response struct {
+		Took     int               `json:"took"`
+		Total    int               `json:"total"`
+		Created  int               `json:"created"`
+		Failures []json.RawMessage `json:"failures"`
+	}
+	err = c.doRequest(ctx, "POST", "/_reindex?refresh=true", bytes.NewReader(body), &response)
+	if err != nil {
+		return err
+	}
+	op := currentOperation(ctx)
+	op.Took = time.Duration(response.Took) * time.Millisecond
+	op.Hits = response.Created
+	if len(response.Failures) > 0 {
+		return errors.Errorf("copying %d documents from %s to %s failed: %s", len(response.Failures), result.Previous, result.Index, response.Failures[0])
+	}
+	result.Copied = response.Created
+	return nil
+}
+
+// pointAlias points the alias to the index instead of the live ones atomically
+// A concrete legacy index named like the alias is deleted in the same request
+func (c *Client[T]) pointAlias(ctx context.Context, index string, live []string, legacy bool) error {
+	var actions []map[string]interface{}
+	for _, l := range live {
+		actions = append(actions, map[string]interface{}{"remove": map[string]string{"index": l, "alias": c.model.Index}})
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]string{"index": index, "alias": c.model.Index}})
+	if legacy {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]string{"index": c.model.Index}})
+	}
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	var response indexManipulationResponse
+	err = c.doRequest(idempotent(ctx), "POST", "/_aliases", bytes.NewReader(body), &response)
+	if err != nil {
+		return err
+	}
+	if !response.Acknowledged {
+		return errors.Errorf("pointing alias %s to %s not acknowledged", c.model.Index, index)
+	}
+	return nil
+}
+
+// blockWrites sets or removes the write block of the indices
+func (c *Client[T]) blockWrites(ctx context.Context, block bool, indices ...string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"index.blocks.write": block})
+	if err != nil {
+		return err
+	}
+	var response indexManipulationResponse
+	err = c.doRequest(idempotent(ctx), "PUT", fmt.Sprintf("/%s/_settings", strings.Join(indices, ",")), bytes.NewReader(body), &response)
+	if err != nil {
+		return err
+	}
+	if !response.Acknowledged {
+		return errors.Errorf("blocking writes to %s not acknowledged", strings.Join(indices, ","))
+	}
+	return nil
+}
+
+// pruneIndexVersions deletes the old index versions except keep of them, the
+// previous live one first and then the most recent ones
+func (c *Client[T]) pruneIndexVersions(ctx context.Context, old []IndexVersion, previous string, keep int) ([]string, error) {
+	var ordered []string
+	for i := len(old) - 1; i >= 0; i-- {
+		if old[i].Name == previous {
+			ordered = append([]string{previous}, ordered...)
+		} else {
+			ordered = append(ordered, old[i].Name)
+		}
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	if len(ordered) <= keep {
+		return nil, nil
+	}
+	prune := ordered[keep:]
+	var response indexManipulationResponse
+	err := c.doRequest(ctx, "DELETE", "/"+strings.Join(prune, ","), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	return prune, nil
+}
@@ -0,0 +1,348 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// fakeIndex is an index of the fakeCluster
+type fakeIndex struct {
+	aliases []string
+	blocked bool     // whether writes are blocked
+	docs    []string // IDs of the documents
+}
+
+// fakeCluster is a fake of the index, alias and _reindex APIs used by versioned clients
+type fakeCluster struct {
+	mu          sync.Mutex
+	indices     map[string]*fakeIndex
+	failReindex bool
+	deleted     []string
+	script      string // script of the last _reindex request
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "GET" && len(parts) == 2 && parts[1] == "_alias":
+		prefix := strings.TrimSuffix(parts[0], "*")
+		res := map[string]interface{}{}
+		for name, idx := range f.indices {
+			if strings.HasPrefix(name, prefix) {
+				aliases := map[string]interface{}{}
+				for _, a := range idx.aliases {
+					aliases[a] = map[string]interface{}{}
+				}
+				res[name] = map[string]interface{}{"aliases": aliases}
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+	case r.Method == "HEAD" && len(parts) == 1:
+		if f.resolve(parts[0]) == nil {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == "PUT" && len(parts) == 1:
+		f.indices[parts[0]] = &fakeIndex{}
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == "PUT" && len(parts) == 2 && parts[1] == "_settings":
+		var settings map[string]bool
+		json.NewDecoder(r.Body).Decode(&settings)
+		for _, name := range strings.Split(parts[0], ",") {
+			f.indices[name].blocked = settings["index.blocks.write"]
+		}
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == "POST" && parts[0] == "_reindex":
+		var body struct {
+			Source struct{ Index string } `json:"source"`
+			Dest   struct{ Index string } `json:"dest"`
+			Script json.RawMessage        `json:"script"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.script = string(body.Script)
+		src := f.indices[body.Source.Index].docs
+		if f.failReindex {
+			fmt.Fprintf(w, `{"took":3,"total":%d,"created":0,"failures":[{"cause":{"type":"mapper_parsing_exception"}}]}`, len(src))
+			return
+		}
+		f.indices[body.Dest.Index].docs = append([]string{}, src...)
+		fmt.Fprintf(w, `{"took":3,"total":%d,"created":%d,"failures":[]}`, len(src), len(src))
+	case r.Method == "POST" && parts[0] == "_aliases":
+		var body struct {
+			Actions []map[string]struct{ Index, Alias string } `json:"actions"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, a := range body.Actions {
+			for action, target := range a {
+				idx := f.indices[target.Index]
+				switch action {
+				case "add":
+					idx.aliases = append(idx.aliases, target.Alias)
+				case "remove":
+					idx.aliases = nil
+				case "remove_index":
+					delete(f.indices, target.Index)
+				}
+			}
+		}
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == "DELETE" && len(parts) == 1:
+		for _, name := range strings.Split(parts[0], ",") {
+			delete(f.indices, name)
+			f.deleted = append(f.deleted, name)
+		}
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == "POST" && len(parts) == 2 && parts[1] == "_refresh":
+		fmt.Fprint(w, `{"_shards":{"total":1,"successful":1,"failed":0}}`)
+	case r.Method == "POST" && len(parts) == 3:
+		idx := f.resolve(parts[0])
+		if idx.blocked {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"type":"cluster_block_exception","reason":"index [docs] blocked by: [FORBIDDEN/8/index write (api)];"},"status":403}`)
+			return
+		}
+		idx.docs = append(idx.docs, parts[2])
+		fmt.Fprintf(w, `{"_id":%q,"result":"created"}`, parts[2])
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":{"type":"unexpected_request","reason":"%s %s"},"status":400}`, r.Method, r.URL.Path)
+	}
+}
+
+// resolve returns the index with the name or alias, f.mu must be held
+func (f *fakeCluster) resolve(name string) *fakeIndex {
+	if idx, ok := f.indices[name]; ok {
+		return idx
+	}
+	for _, idx := range f.indices {
+		for _, a := range idx.aliases {
+			if a == name {
+				return idx
+			}
+		}
+	}
+	return nil
+}
+
+// state describes the indices like docs_v1:a,b:live:blocked, sorted by their names
+func (f *fakeCluster) state() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var state []string
+	for name, idx := range f.indices {
+		s := name + ":" + strings.Join(idx.docs, ",")
+		if len(idx.aliases) > 0 {
+			s += ":live"
+		}
+		if idx.blocked {
+			s += ":blocked"
+		}
+		state = append(state, s)
+	}
+	sort.Strings(state)
+	return strings.Join(state, " ")
+}
+
+// newFakeCluster returns a cluster with the indices, named like docs_v1 or docs_v2:live
+// All indices contain the documents a and b
+func newFakeCluster(indices ...string) *fakeCluster {
+	f := &fakeCluster{indices: map[string]*fakeIndex{}}
+	for _, i := range indices {
+		name, live := strings.CutSuffix(i, ":live")
+		f.indices[name] = &fakeIndex{docs: []string{"a", "b"}}
+		if live {
+			f.indices[name].aliases = []string{"docs"}
+		}
+	}
+	return f
+}
+
+func TestReindex(t *testing.T) {
+	tests := []struct {
+		name        string
+		indices     []string
+		opts        []ReindexOption[testDoc]
+		failReindex bool
+		want        *ReindexResult // nil if Reindex has to fail
+		state       string
+	}{
+		{
+			name:    "first version",
+			indices: nil,
+			want:    &ReindexResult{Index: "docs_v1"},
+			state:   "docs_v1::live",
+		},
+		{
+			name:    "concrete index is replaced",
+			indices: []string{"docs"},
+			want:    &ReindexResult{Index: "docs_v1", Previous: "docs", Copied: 2},
+			state:   "docs_v1:a,b:live",
+		},
+		{
+			name:    "old versions are pruned",
+			indices: []string{"docs_v1", "docs_v2", "docs_v3:live", "docs_vip_v1"},
+			want:    &ReindexResult{Index: "docs_v4", Previous: "docs_v3", Copied: 2, Deleted: []string{"docs_v2", "docs_v1"}},
+			state:   "docs_v3:a,b:blocked docs_v4:a,b:live docs_vip_v1:a,b",
+		},
+		{
+			name:    "more versions are kept",
+			indices: []string{"docs_v1", "docs_v2:live"},
+			opts:    []ReindexOption[testDoc]{WithKeepIndexVersions[testDoc](2)},
+			want:    &ReindexResult{Index: "docs_v3", Previous: "docs_v2", Copied: 2},
+			state:   "docs_v1:a,b docs_v2:a,b:blocked docs_v3:a,b:live",
+		},
+		{
+			name:    "no versions are kept",
+			indices: []string{"docs_v1", "docs_v2:live"},
+			opts:    []ReindexOption[testDoc]{WithKeepIndexVersions[testDoc](0)},
+			want:    &ReindexResult{Index: "docs_v3", Previous: "docs_v2", Copied: 2, Deleted: []string{"docs_v2", "docs_v1"}},
+			state:   "docs_v3:a,b:live",
+		},
+		{
+			name:        "failed copy keeps the live version",
+			indices:     []string{"docs_v1", "docs_v2:live"},
+			failReindex: true,
+			state:       "docs_v1:a,b docs_v2:a,b:live",
+		},
+		{
+			name:    "failed loader keeps the live version",
+			indices: []string{"docs_v1:live"},
+			opts: []ReindexOption[testDoc]{WithReindexLoader(func(ctx context.Context, c *Client[testDoc]) error {
+				return errors.New("source unavailable")
+			})},
+			state: "docs_v1:a,b:live",
+		},
+		{
+			name:    "loader",
+			indices: []string{"docs_v1:live"},
+			opts: []ReindexOption[testDoc]{WithReindexLoader(func(ctx context.Context, c *Client[testDoc]) error {
+				_, err := c.Index(ctx, &testDoc{ID: "c"})
+				return err
+			})},
+			want:  &ReindexResult{Index: "docs_v2", Previous: "docs_v1"},
+			state: "docs_v1:a,b:blocked docs_v2:c:live",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newFakeCluster(tt.indices...)
+			cluster.failReindex = tt.failReindex
+			c, _ := newTestClient(t, cluster)
+			c.SetVersioned()
+
+			result, err := c.Reindex(context.Background(), tt.opts...)
+
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", result)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if fmt.Sprintf("%+v", result) != fmt.Sprintf("%+v", tt.want) {
+				t.Errorf("expected result %+v, got %+v", tt.want, result)
+			}
+			if state := cluster.state(); state != tt.state {
+				t.Errorf("expected indices %s, got %s", tt.state, state)
+			}
+		})
+	}
+}
+
+func TestReindexBlocksWrites(t *testing.T) {
+	cluster := newFakeCluster("docs_v1:live")
+	c, _ := newTestClient(t, cluster)
+	c.SetVersioned()
+	var writeErr error
+
+	_, err := c.Reindex(context.Background(), WithReindexLoader(func(ctx context.Context, target *Client[testDoc]) error {
+		// a write to the live version, which wouldn't be in the new one
+		_, writeErr = c.Index(ctx, &testDoc{ID: "c"})
+		return nil
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(writeErr, ErrUnavailable) {
+		t.Errorf("expected the write to fail with ErrUnavailable, got %v", writeErr)
+	}
+	_, err = c.Index(context.Background(), &testDoc{ID: "d"})
+	if err != nil {
+		t.Fatalf("expected writes to the new version, got %v", err)
+	}
+	if state, want := cluster.state(), "docs_v1:a,b:blocked docs_v2:d:live"; state != want {
+		t.Errorf("expected indices %s, got %s", want, state)
+	}
+}
+
+func TestRollbackIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		indices []string
+		err     error
+		state   string
+	}{
+		{"previous version", []string{"docs_v1", "docs_v2", "docs_v3:live"}, nil, "docs_v1:a,b docs_v2:a,b:live docs_v3:a,b"},
+		{"no previous version", []string{"docs_v1:live"}, ErrNoPreviousIndexVersion, "docs_v1:a,b:live"},
+		{"no live version", []string{"docs_v1"}, ErrNoPreviousIndexVersion, "docs_v1:a,b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newFakeCluster(tt.indices...)
+			for _, idx := range cluster.indices {
+				if len(idx.aliases) == 0 {
+					idx.blocked = true
+				}
+			}
+			c, _ := newTestClient(t, cluster)
+			c.SetVersioned()
+
+			err := c.RollbackIndex(context.Background())
+
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			state := strings.ReplaceAll(cluster.state(), ":blocked", "")
+			if tt.err == nil && strings.Contains(cluster.state(), "live:blocked") {
+				t.Errorf("expected the write block of the previous version to be removed, got %s", cluster.state())
+			}
+			if state != tt.state {
+				t.Errorf("expected indices %s, got %s", tt.state, state)
+			}
+		})
+	}
+}
+
+func TestReindexStoresIDs(t *testing.T) {
+	for _, store := range []bool{false, true} {
+		t.Run(fmt.Sprint(store), func(t *testing.T) {
+			cluster := newFakeCluster("docs_v1:live")
+			c, _ := newTestClient(t, cluster)
+			c.SetVersioned()
+			c.model.IDPath = "id"
+			c.model.StoreID = store
+
+			_, err := c.Reindex(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := ""
+			if store {
+				want = `{"params":{"path":"id"},"source":"ctx._source[params.path] = ctx._id"}`
+			}
+			if cluster.script != want {
+				t.Errorf("expected script %s, got %s", want, cluster.script)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package runtime
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// SetBasicAuth authenticates the requests with basic auth
+func (c *Client[T]) SetBasicAuth(user, password string) {
+	c.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// SetAPIKey authenticates the requests with the API key, encoded as returned by the _security/api_key API
+func (c *Client[T]) SetAPIKey(key string) {
+	c.auth = "ApiKey " + key
+}
+
+// SetBearerToken authenticates the requests with the bearer token
+func (c *Client[T]) SetBearerToken(token string) {
+	c.auth = "Bearer " + token
+}
+
+// tlsOptions are the TLS options of a Client. They are applied to a clone of its transport,
+// regardless of whether the transport is set before or after them
+type tlsOptions struct {
+	rootCAs      *x509.CertPool
+	certificates []tls.Certificate
+	insecure     *bool
+}
+
+// SetCACert sets the PEM encoded certificates of the certificate authorities, which
+// are trusted to sign the certificates of the nodes, instead of the system ones
+// When it fails, the error is returned for all requests as well
+func (c *Client[T]) SetCACert(pem []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return c.fail(errors.New("no certificate found in the CA certificates"))
+	}
+	return c.setTLS(func(o *tlsOptions) {
+		o.rootCAs = pool
+	})
+}
+
+// SetCACertFile sets the certificates of the certificate authorities to those of the PEM file, see SetCACert
+func (c *Client[T]) SetCACertFile(path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return c.fail(errors.Wrap(err, "couldn't read CA certificates"))
+	}
+	return c.SetCACert(pem)
+}
+
+// SetClientCert authenticates the client with the PEM encoded certificate and key
+// When it fails, the error is returned for all requests as well
+func (c *Client[T]) SetClientCert(certPEM, keyPEM []byte) error {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		// the error of tls.X509KeyPair doesn't contain the key
+		return c.fail(errors.Wrap(err, "invalid client certificate"))
+	}
+	return c.setTLS(func(o *tlsOptions) {
+		o.certificates = []tls.Certificate{cert}
+	})
+}
+
+// SetClientCertFiles authenticates the client with the certificate and key of the PEM files, see SetClientCert
+func (c *Client[T]) SetClientCertFiles(certFile, keyFile string) error {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return c.fail(errors.Wrap(err, "couldn't read client certificate"))
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return c.fail(errors.Wrap(err, "couldn't read client key"))
+	}
+	return c.SetClientCert(certPEM, keyPEM)
+}
+
+// SetInsecureSkipVerify disables the verification of the certificates of the nodes
+// It must only be used for development
+func (c *Client[T]) SetInsecureSkipVerify(skip bool) error {
+	return c.setTLS(func(o *tlsOptions) {
+		o.insecure = &skip
+	})
+}
+
+// setTLS changes a copy of the TLS options, which may be shared with copies of the client, and applies them
+func (c *Client[T]) setTLS(f func(*tlsOptions)) error {
+	var o tlsOptions
+	if c.tls != nil {
+		o = *c.tls
+	}
+	f(&o)
+	c.tls = &o
+	return c.applyTLS()
+}
+
+// applyTLS sends the requests with the transport, or with a clone of it configured
+// with the TLS options. Other clients sharing the transport aren't affected
+// TLS options can only be applied to an *http.Transport, otherwise the requests fail
+func (c *Client[T]) applyTLS() error {
+	h := *c.http
+	h.Transport = c.transport
+	defer func() { c.http = &h }()
+	if c.tls == nil {
+		return nil
+	}
+	t := c.transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	ht, ok := t.(*http.Transport)
+	if !ok {
+		return c.fail(errors.Errorf("TLS options can't be applied to a transport of type %T, configure TLS in the transport instead", t))
+	}
+	ht = ht.Clone()
+	if ht.TLSClientConfig == nil {
+		ht.TLSClientConfig = &tls.Config{}
+	}
+	if c.tls.rootCAs != nil {
+		ht.TLSClientConfig.RootCAs = c.tls.rootCAs
+	}
+	if c.tls.certificates != nil {
+		ht.TLSClientConfig.Certificates = c.tls.certificates
+	}
+	if c.tls.insecure != nil {
+		ht.TLSClientConfig.InsecureSkipVerify = *c.tls.insecure
+	}
+	h.Transport = ht
+	return nil
+}
+
+// fail sets the error of the configuration, which is returned for all requests
+func (c *Client[T]) fail(err error) error {
+	c.err = err
+	return err
+}
@@ -0,0 +1,135 @@
+package runtime
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	// the failed handshakes are expected
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	shared := &http.Transport{}
+	tests := []struct {
+		name  string
+		setup func(c *Client[testDoc])
+		err   string // expected part of the error of the request, empty for success
+	}{
+		{
+			name:  "unknown certificate authority",
+			setup: func(c *Client[testDoc]) {},
+			err:   "certificate",
+		},
+		{
+			name: "custom CA",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetCACert(ca)
+			},
+		},
+		{
+			name: "insecure",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetInsecureSkipVerify(true)
+			},
+		},
+		{
+			name: "transport set after the CA",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetCACert(ca)
+				c.SetTransport(shared)
+			},
+		},
+		{
+			name: "transport set before the CA",
+			setup: func(c *Client[testDoc]) {
+				c.SetTransport(shared)
+				_ = c.SetCACert(ca)
+			},
+		},
+		{
+			name: "HTTP client set after insecure",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetInsecureSkipVerify(true)
+				c.SetHTTPClient(&http.Client{Transport: shared})
+			},
+		},
+		{
+			name: "transport, which isn't an http.Transport",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetCACert(ca)
+				c.SetTransport(roundTripperFunc(http.DefaultTransport.RoundTrip))
+			},
+			err: "TLS options can't be applied",
+		},
+		{
+			name: "invalid CA",
+			setup: func(c *Client[testDoc]) {
+				_ = c.SetCACert([]byte("no pem"))
+			},
+			err: "no certificate found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(srv.URL, testModel, 5*time.Second)
+			tt.setup(c)
+			_, err := c.IndexExists(context.Background())
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("expected no error, got %s", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+			}
+			if cfg := shared.TLSClientConfig; cfg != nil && (cfg.RootCAs != nil || cfg.InsecureSkipVerify) {
+				t.Errorf("the TLS options have been applied to the shared transport")
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *Client[testDoc])
+		want  string // expected Authorization header
+	}{
+		{"none", func(c *Client[testDoc]) {}, ""},
+		{"basic auth", func(c *Client[testDoc]) { c.SetBasicAuth("elastic", "secret") }, "Basic ZWxhc3RpYzpzZWNyZXQ="},
+		{"API key", func(c *Client[testDoc]) { c.SetAPIKey("a2V5") }, "ApiKey a2V5"},
+		{"bearer token", func(c *Client[testDoc]) { c.SetBearerToken("token") }, "Bearer token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+			}))
+			tt.setup(c)
+			_, err := c.IndexExists(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected Authorization %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
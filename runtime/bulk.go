@@ -0,0 +1,289 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BulkAction is the action of a bulk operation
+type BulkAction string
+
+// actions of bulk operations
+const (
+	BulkActionIndex  BulkAction = "index"
+	BulkActionDelete BulkAction = "delete"
+)
+
+// BulkOp is a single operation of a bulk request
+type BulkOp[T any] struct {
+	Action BulkAction
+	ID     string // _id of the document, it's taken from Doc for index operations
+	Doc    *T     // Document to index, nil for delete operations
+}
+
+// IndexOp creates or updates the document in a bulk request
+func IndexOp[T any](doc *T) BulkOp[T] {
+	return BulkOp[T]{Action: BulkActionIndex, Doc: doc}
+}
+
+// DeleteOp deletes the document with the given _id in a bulk request
+func DeleteOp[T any](id string) BulkOp[T] {
+	return BulkOp[T]{Action: BulkActionDelete, ID: id}
+}
+
+// default limits of a single bulk request, see WithBulkMaxDocs and WithBulkMaxBytes
+const (
+	DefaultBulkMaxDocs  = 1000
+	DefaultBulkMaxBytes = 5 << 20
+)
+
+// BulkConfig configures a call of Bulk
+type BulkConfig struct {
+	MaxDocs  int    // Maximum number of operations per request
+	MaxBytes int    // Maximum size of the body per request, a single bigger operation is sent alone
+	Refresh  string // Value of the refresh parameter
+}
+
+// BulkOption is an option for Bulk
+type BulkOption func(*BulkConfig)
+
+// WithBulkMaxDocs splits the operations into requests of at most n operations
+func WithBulkMaxDocs(n int) BulkOption {
+	return func(cfg *BulkConfig) {
+		cfg.MaxDocs = n
+	}
+}
+
+// WithBulkMaxBytes splits the operations into requests with bodies of at most n bytes
+func WithBulkMaxBytes(n int) BulkOption {
+	return func(cfg *BulkConfig) {
+		cfg.MaxBytes = n
+	}
+}
+
+// ForceBulkRefresh forces the immediate refresh after each bulk request
+func ForceBulkRefresh(cfg *BulkConfig) {
+	cfg.Refresh = "true"
+}
+
+// BulkItem is the result of a single operation of a bulk request
+type BulkItem struct {
+	Action BulkAction `json:"-"`
+	ID     string     `json:"_id"`
+	Status int        `json:"status"`
+	Result string     `json:"result"`
+	Error  *Error     `json:"error"`
+}
+
+// Failed returns whether the operation failed
+func (i BulkItem) Failed() bool {
+	return i.Error != nil || i.Status >= 300
+}
+
+// BulkResult contains the results of all operations of Bulk, in the order of the operations
+type BulkResult struct {
+	Items []BulkItem
+}
+
+// Failed returns the results of the failed operations
+func (r *BulkResult) Failed() []BulkItem {
+	var failed []BulkItem
+	for _, i := range r.Items {
+		if i.Failed() {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// Err returns a *BulkError, if any operation failed
+func (r *BulkResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BulkError{Items: failed}
+}
+
+// BulkError reports the failed operations of a bulk request
+type BulkError struct {
+	Items []BulkItem
+}
+
+func (e *BulkError) Error() string {
+	msgs := make([]string, 0, len(e.Items))
+	for _, i := range e.Items {
+		reason := i.Result
+		if i.Error != nil {
+			reason = i.Error.Type + ": " + i.Error.Reason
+		}
+		msgs = append(msgs, fmt.Sprintf("%s %q: %d %s", i.Action, i.ID, i.Status, reason))
+	}
+	return fmt.Sprintf("%d bulk operations failed: %s", len(e.Items), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed operations, to check them with errors.Is and errors.As
+func (e *BulkError) Unwrap() []error {
+	var errs []error
+	for _, i := range e.Items {
+		if i.Error != nil {
+			errs = append(errs, i.Error)
+			continue
+		}
+		errs = append(errs, &Error{Status: i.Status, Reason: i.Result})
+	}
+	return errs
+}
+
+// BulkIndex creates or updates the documents, see Bulk
+func (c *Client[T]) BulkIndex(ctx context.Context, docs []*T, opts ...BulkOption) (_ *BulkResult, err error) {
+	ctx, finish := c.operation(ctx, "BulkIndex")
+	defer func() { finish(err) }()
+	ops := make([]BulkOp[T], len(docs))
+	for n, d := range docs {
+		ops[n] = IndexOp(d)
+	}
+	return c.Bulk(ctx, ops, opts...)
+}
+
+// BulkDelete deletes the documents with the given _ids, see Bulk
+func (c *Client[T]) BulkDelete(ctx context.Context, ids []string, opts ...BulkOption) (_ *BulkResult, err error) {
+	ctx, finish := c.operation(ctx, "BulkDelete")
+	defer func() { finish(err) }()
+	ops := make([]BulkOp[T], len(ids))
+	for n, id := range ids {
+		ops[n] = DeleteOp[T](id)
+	}
+	return c.Bulk(ctx, ops, opts...)
+}
+
+// Bulk sends the operations to the _bulk API, split into requests by the limits of the BulkConfig
+// The returned error only reports failed requests, the failures of single operations are
+// reported by the BulkResult, see BulkResult.Err. The _ids of indexed documents are set to them
+func (c *Client[T]) Bulk(ctx context.Context, ops []BulkOp[T], opts ...BulkOption) (_ *BulkResult, err error) {
+	ctx, finish := c.operation(ctx, "Bulk")
+	defer func() { finish(err) }()
+	cfg := BulkConfig{MaxDocs: DefaultBulkMaxDocs, MaxBytes: DefaultBulkMaxBytes, Refresh: "false"}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	result := &BulkResult{Items: make([]BulkItem, 0, len(ops))}
+	body := &bytes.Buffer{}
+	start := 0
+	for n, op := range ops {
+		line, err := c.encodeBulkOp(op)
+		if err != nil {
+			return result, errors.Wrapf(err, "couldn't encode bulk operation %d", n)
+		}
+		full := n > start && (n-start >= cfg.MaxDocs || body.Len()+len(line) > cfg.MaxBytes)
+		if full {
+			err = c.sendBulk(ctx, ops[start:n], body, cfg, result)
+			if err != nil {
+				return result, err
+			}
+			body.Reset()
+			start = n
+		}
+		body.Write(line)
+	}
+	if start < len(ops) {
+		err := c.sendBulk(ctx, ops[start:], body, cfg, result)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// bulkIdempotent returns whether the operations can be retried, which is the case if they don't index documents without ID
+func (c *Client[T]) bulkIdempotent(ops []BulkOp[T]) bool {
+	for _, op := range ops {
+		if op.Action == BulkActionIndex && c.model.ID(op.Doc) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeBulkOp encodes the operation as NDJSON lines of a bulk request body
+func (c *Client[T]) encodeBulkOp(op BulkOp[T]) ([]byte, error) {
+	id := op.ID
+	if op.Action == BulkActionIndex {
+		if op.Doc == nil {
+			return nil, errors.New("index operation without document")
+		}
+		var err error
+		id, err = c.documentID(op.Doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	meta := map[string]interface{}{}
+	if id != "" {
+		meta["_id"] = id
+	}
+	b := &bytes.Buffer{}
+	err := json.NewEncoder(b).Encode(map[string]interface{}{string(op.Action): meta})
+	if err != nil {
+		return nil, err
+	}
+	if op.Action == BulkActionIndex {
+		doc, err := c.encodeDocument(op.Doc, id)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(doc)
+	}
+	return b.Bytes(), nil
+}
+
+// sendBulk sends a single bulk request for the operations, appending their results to result
+func (c *Client[T]) sendBulk(ctx context.Context, ops []BulkOp[T], body *bytes.Buffer, cfg BulkConfig, result *BulkResult) error {
+	if c.bulkIdempotent(ops) {
+		ctx = idempotent(ctx)
+	}
+	req, err := c.newRequest(ctx, "POST", fmt.Sprintf("%s/_bulk?refresh=%s", c.typePath, cfg.Refresh), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	var response struct {
+		Took  int                       `json:"took"`
+		Items []map[BulkAction]BulkItem `json:"items"`
+		Error *Error                    `json:"error"`
+	}
+	err = c.do(req, &response)
+	if err != nil {
+		return err
+	}
+	op := currentOperation(ctx)
+	op.Took += time.Duration(response.Took) * time.Millisecond
+	op.Hits += len(ops)
+	if response.Error != nil {
+		return response.Error
+	}
+	if len(response.Items) != len(ops) {
+		return fmt.Errorf("bulk response contains %d items for %d operations", len(response.Items), len(ops))
+	}
+	for n, op := range ops {
+		item := response.Items[n][op.Action]
+		item.Action = op.Action
+		if item.Error != nil {
+			item.Error.Status = item.Status
+		}
+		if op.Action == BulkActionIndex && !item.Failed() {
+			err = c.model.SetID(op.Doc, item.ID)
+			if err != nil {
+				return err
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return nil
+}
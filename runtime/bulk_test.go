@@ -0,0 +1,153 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBulk(t *testing.T) {
+	tests := []struct {
+		name     string
+		ops      string // operations separated by commas, index:ID or delete:ID, index: for a document without ID
+		opts     []BulkOption
+		statuses string   // statuses of the items of all requests separated by commas, 201 by default
+		fail     int      // number of the request, which fails with 400, 0 for none
+		requests []int    // expected number of operations per request
+		items    string   // expected results separated by commas, like index:1:201
+		ids      []string // expected IDs of the indexed documents
+		failed   int      // expected number of failed operations reported by BulkResult.Err
+		err      bool     // whether Bulk has to fail
+	}{
+		{
+			name:     "single request",
+			ops:      "index:1,index:2,index:3",
+			requests: []int{3},
+			items:    "index:1:201,index:2:201,index:3:201",
+			ids:      []string{"1", "2", "3"},
+		},
+		{
+			name:     "split by MaxDocs",
+			ops:      "index:1,index:2,index:3,index:4,index:5",
+			opts:     []BulkOption{WithBulkMaxDocs(2)},
+			requests: []int{2, 2, 1},
+			items:    "index:1:201,index:2:201,index:3:201,index:4:201,index:5:201",
+			ids:      []string{"1", "2", "3", "4", "5"},
+		},
+		{
+			name: "split by MaxBytes",
+			ops:  "index:1,index:2,index:3",
+			// an index operation of the tests has 38 bytes
+			opts:     []BulkOption{WithBulkMaxBytes(80)},
+			requests: []int{2, 1},
+			items:    "index:1:201,index:2:201,index:3:201",
+			ids:      []string{"1", "2", "3"},
+		},
+		{
+			name:     "bigger operation sent alone",
+			ops:      "index:1,delete:2,index:3",
+			opts:     []BulkOption{WithBulkMaxBytes(10)},
+			requests: []int{1, 1, 1},
+			items:    "index:1:201,delete:2:200,index:3:201",
+			ids:      []string{"1", "", "3"},
+			statuses: "201,200,201",
+		},
+		{
+			name:     "failed items",
+			ops:      "index:1,delete:2,index:",
+			statuses: "201,404,201",
+			requests: []int{3},
+			items:    "index:1:201,delete:2:404,index:generated2:201",
+			ids:      []string{"1", "", "generated2"},
+			failed:   1,
+		},
+		{
+			name:     "failed items in several requests",
+			ops:      "index:1,index:2,index:3,index:4",
+			opts:     []BulkOption{WithBulkMaxDocs(2)},
+			statuses: "201,429,400,201",
+			requests: []int{2, 2},
+			items:    "index:1:201,index:2:429,index:3:400,index:4:201",
+			ids:      []string{"1", "2", "3", "4"},
+			failed:   2,
+		},
+		{
+			name:     "failed request",
+			ops:      "index:1,index:2,index:3",
+			opts:     []BulkOption{WithBulkMaxDocs(2)},
+			fail:     2,
+			requests: []int{2, 1},
+			items:    "index:1:201,index:2:201",
+			ids:      []string{"1", "2", "3"},
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := strings.Split(tt.statuses, ",")
+			var requests []int
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if len(requests)+1 == tt.fail {
+					body, _ := io.ReadAll(r.Body)
+					requests = append(requests, strings.Count(string(body), `{"index"`)+strings.Count(string(body), `{"delete"`))
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprint(w, `{"error":{"type":"illegal_argument_exception","reason":"failed"},"status":400}`)
+					return
+				}
+				n := writeBulkResponse(t, w, r, statuses)
+				statuses = statuses[min(n, len(statuses)):]
+				requests = append(requests, n)
+			}))
+			var ops []BulkOp[testDoc]
+			var docs []*testDoc
+			for _, op := range strings.Split(tt.ops, ",") {
+				action, id, _ := strings.Cut(op, ":")
+				if action == "delete" {
+					ops = append(ops, DeleteOp[testDoc](id))
+					docs = append(docs, nil)
+					continue
+				}
+				doc := &testDoc{ID: id, Name: "doc" + id}
+				ops = append(ops, IndexOp(doc))
+				docs = append(docs, doc)
+			}
+
+			result, err := c.Bulk(context.Background(), ops, tt.opts...)
+
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if fmt.Sprint(requests) != fmt.Sprint(tt.requests) {
+				t.Errorf("expected requests with %v operations, got %v", tt.requests, requests)
+			}
+			var items []string
+			for _, i := range result.Items {
+				items = append(items, fmt.Sprintf("%s:%s:%d", i.Action, i.ID, i.Status))
+			}
+			if strings.Join(items, ",") != tt.items {
+				t.Errorf("expected items %s, got %s", tt.items, strings.Join(items, ","))
+			}
+			for n, d := range docs {
+				if d != nil && d.ID != tt.ids[n] {
+					t.Errorf("expected ID %q of document %d, got %q", tt.ids[n], n, d.ID)
+				}
+			}
+			var bulkErr *BulkError
+			if tt.failed == 0 {
+				if result.Err() != nil {
+					t.Errorf("expected no failed operations, got %v", result.Err())
+				}
+			} else if !errors.As(result.Err(), &bulkErr) || len(bulkErr.Items) != tt.failed {
+				t.Errorf("expected %d failed operations, got %v", tt.failed, result.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Package runtime contains the logic shared by all clients generated by slimlastic
+// The generated code only contains a thin typed wrapper around Client
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Model describes the struct a Client handles and where it's stored in elasticsearch
+type Model[T any] struct {
+	Index           string                 // Name of the elasticsearch index
+	Type            string                 // Name of the elasticsearch document type
+	IndexDefinition string                 // Definition of the index, used when it's created
+	SearchFields    []string               // Fields Search matches the text against, with optional boosts like title^2
+	IDPath          string                 // Path of the ID in the documents, the tiebreaker of GetPage, defaults to _id, which elasticsearch 7.6+ can't sort by
+	StoreID         bool                   // Whether the ID is written to the documents at IDPath, for models without it in their JSON, see Client.Index
+	ID              func(*T) string        // Returns the _id of the document, an empty string for new documents
+	SetID           func(*T, string) error // Sets the _id to the document
+	GeneratedIDs    bool                   // Whether elasticsearch generates the _id of new documents, SetID has to accept its random strings
+}
+
+// Client is an elasticsearch client dedicated to the struct T
+type Client[T any] struct {
+	http            *http.Client
+	model           Model[T]
+	pool            *pool
+	indexPath       string
+	typePath        string
+	cursorKey       []byte
+	retry           RetryPolicy
+	middleware      []Middleware
+	instrumentation []Instrumentation
+	versioned       bool   // whether the index name is an alias of versioned indices, see SetVersioned
+	auth            string // value of the Authorization header, it must never be logged
+	err             error  // error of the configuration, returned for all requests
+	// transport set by SetHTTPClient or SetTransport, the TLS options are applied to a clone of it
+	transport http.RoundTripper
+	tls       *tlsOptions
+}
+
+// NewClient instantiates a new client for the elasticsearch nodes at url, which
+// can contain multiple URLs separated by commas, see SetNodes
+// It doesn't send any request, see EnsureExistingIndex
+func NewClient[T any](url string, model Model[T], timeout time.Duration) *Client[T] {
+	return &Client[T]{
+		http:      &http.Client{Timeout: timeout},
+		model:     model,
+		pool:      newPool(strings.Split(url, ",")),
+		indexPath: fmt.Sprintf("/%s", model.Index),
+		typePath:  fmt.Sprintf("/%s/%s", model.Index, model.Type),
+		cursorKey: defaultCursorKey,
+	}
+}
+
+// SetTimeout sets the timeout for requests to elasticsearch
+func (c *Client[T]) SetTimeout(d time.Duration) {
+	c.http.Timeout = d
+}
+
+// WithTimeout returns a copy of the client, which uses the given timeout for its requests
+func (c *Client[T]) WithTimeout(d time.Duration) *Client[T] {
+	cp := *c
+	h := *c.http
+	h.Timeout = d
+	cp.http = &h
+	return &cp
+}
+
+// SetHTTPClient sends the requests with a copy of h, to share its transport with other clients
+// The timeout of the client is kept, when h has none. TLS options are applied to a clone of
+// the transport, which has to be an *http.Transport then
+func (c *Client[T]) SetHTTPClient(h *http.Client) {
+	cp := *h
+	if cp.Timeout == 0 {
+		cp.Timeout = c.http.Timeout
+	}
+	c.http = &cp
+	c.transport = cp.Transport
+	_ = c.applyTLS()
+}
+
+// SetTransport sends the requests with the transport, like one which adds tracing. TLS
+// options are applied to a clone of the transport, which has to be an *http.Transport then
+func (c *Client[T]) SetTransport(t http.RoundTripper) {
+	c.transport = t
+	_ = c.applyTLS()
+}
+
+// SetIndex sets the name of the index, overriding the one of the model
+func (c *Client[T]) SetIndex(name string) {
+	c.model.Index = name
+	c.indexPath = fmt.Sprintf("/%s", name)
+	c.typePath = fmt.Sprintf("/%s/%s", name, c.model.Type)
+}
+
+// Refresh refreshes the index, to make all operations performed since the last refresh available for search
+func (c *Client[T]) Refresh(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "Refresh")
+	defer func() { finish(err) }()
+	var result struct {
+		Shards shards `json:"_shards"`
+	}
+	err = c.doRequest(idempotent(ctx), "POST", fmt.Sprintf("%s/_refresh", c.indexPath), nil, &result)
+	if err != nil {
+		return err
+	}
+	if result.Shards.Failed != 0 {
+		return fmt.Errorf("Refreshing of %d shards failed (%d successful; %d in total)", result.Shards.Failed, result.Shards.Successful, result.Shards.Total)
+	}
+	return nil
+}
+
+// newRequest creates a request for the path, which is sent to a node of the pool by send
+func (c *Client[T]) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	return req, nil
+}
+
+func (c *Client[T]) doRequest(ctx context.Context, method, path string, body io.Reader, response interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.do(req, response)
+}
+
+// do sends the request and decodes the JSON response into response
+// Responses with an error object and failed responses, which aren't JSON, are returned as *Error
+// Other responses are decoded regardless of their status, like those of documents not found
+func (c *Client[T]) do(req *http.Request, response interface{}) error {
+	res, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	currentOperation(req.Context()).Status = res.StatusCode
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "couldn't read response")
+	}
+	if res.StatusCode >= 300 {
+		var envelope struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if json.Unmarshal(body, &envelope) == nil && len(envelope.Error) > 0 && string(envelope.Error) != "null" {
+			e := &Error{Status: res.StatusCode}
+			if json.Unmarshal(envelope.Error, e) != nil {
+				// elasticsearch before 5.0 returns the error as string
+				_ = json.Unmarshal(envelope.Error, &e.Reason)
+			}
+			return e
+		}
+	}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		if res.StatusCode >= 300 {
+			return &Error{Status: res.StatusCode, Reason: http.StatusText(res.StatusCode)}
+		}
+		return errors.Wrap(err, "couldn't decode JSON response")
+	}
+	return nil
+}
+
+// send sends the request to a node of the pool, errors of requests, which timed out, are ErrTimeout
+func (c *Client[T]) send(req *http.Request) (*http.Response, error) {
+	res, err := c.sendWithRetries(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, &timeoutError{err}
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// sendWithRetries sends the request and retries it according to the RetryPolicy
+// Requests, which couldn't be sent because the node couldn't be reached, are
+// sent to the next node regardless of the RetryPolicy
+func (c *Client[T]) sendWithRetries(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.pool.sniffDue() {
+		// the current nodes are kept, when sniffing fails
+		_ = c.Sniff(req.Context())
+	}
+	failovers := 0
+	for attempt := 1; ; attempt++ {
+		n, err := c.pool.pick()
+		if err != nil {
+			return nil, err
+		}
+		r, err := nodeRequest(req, n, attempt+failovers == 1)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.doer().Do(r)
+		switch {
+		case err == nil:
+			c.pool.markAlive(n)
+		case isConnectionError(err):
+			c.pool.markDead(n)
+			if isDialError(err) && failovers < c.pool.len()-1 && rewindable(r) {
+				failovers++
+				attempt--
+				continue
+			}
+		}
+		if attempt >= c.retry.MaxAttempts || !c.retry.retryable(r, res, err) {
+			return res, err
+		}
+		ev := RetryEvent{
+			Method:  r.Method,
+			URL:     r.URL.Redacted(),
+			Attempt: attempt,
+			Err:     err,
+			Wait:    backoff(attempt-1, c.retry.Backoff, c.retry.MaxBackoff),
+		}
+		if res != nil {
+			ev.Status = res.StatusCode
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(ev)
+		}
+		t := time.NewTimer(ev.Wait)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
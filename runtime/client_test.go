@@ -0,0 +1,36 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testDoc is the model of the clients in the tests
+type testDoc struct {
+	ID   string `json:"-"`
+	Name string `json:"name"`
+}
+
+var testModel = Model[testDoc]{
+	Index:           "docs",
+	Type:            "doc",
+	IndexDefinition: `{}`,
+	ID: func(d *testDoc) string {
+		return d.ID
+	},
+	SetID: func(d *testDoc, id string) error {
+		d.ID = id
+		return nil
+	},
+	GeneratedIDs: true,
+}
+
+// newTestClient returns a client for a test server with the handler
+func newTestClient(t *testing.T, h http.Handler) (*Client[testDoc], *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, testModel, 5*time.Second), srv
+}
@@ -0,0 +1,275 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GetOneByID returns the document with the given _id
+func (c *Client[T]) GetOneByID(ctx context.Context, id string) (_ *T, err error) {
+	ctx, finish := c.operation(ctx, "GetOneByID")
+	defer func() { finish(err) }()
+	var response struct {
+		ID     string `json:"_id"`
+		Source T      `json:"_source"`
+		Found  bool   `json:"found"`
+	}
+	err = c.doRequest(ctx, "GET", fmt.Sprintf("%s/%s", c.typePath, url.PathEscape(id)), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Found {
+		return nil, &Error{Status: http.StatusNotFound, Reason: fmt.Sprintf("%s with id %q not found", c.model.Type, id), Index: c.model.Index}
+	}
+	err = c.model.SetID(&response.Source, response.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Source, nil
+}
+
+// GetList returns the documents of the index, paginated by offset and limit
+func (c *Client[T]) GetList(ctx context.Context, offset, limit int) (_ []T, err error) {
+	ctx, finish := c.operation(ctx, "GetList")
+	defer func() { finish(err) }()
+	return c.DoListRequest(ctx, strings.NewReader(fmt.Sprintf(`{"from":%d,"size":%d}`, offset, limit)))
+}
+
+// DoListRequest sends the search request body and returns the found documents
+func (c *Client[T]) DoListRequest(ctx context.Context, body io.Reader, opts ...ListOption) (_ []T, err error) {
+	ctx, finish := c.operation(ctx, "DoListRequest")
+	defer func() { finish(err) }()
+	var cfg ListOptions
+	for _, o := range opts {
+		o(&cfg)
+	}
+	hits, err := c.search(ctx, body, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]T, len(hits))
+	for n, h := range hits {
+		res[n] = h.Source
+	}
+	return res, nil
+}
+
+// Search runs a full text search for txt over the SearchFields of the model
+// The hits are ordered by their score and paginated by offset and limit
+func (c *Client[T]) Search(ctx context.Context, txt string, offset, limit int, opts ...ListOption) (_ []Hit[T], err error) {
+	ctx, finish := c.operation(ctx, "Search")
+	defer func() { finish(err) }()
+	var cfg ListOptions
+	for _, o := range opts {
+		o(&cfg)
+	}
+	match := map[string]interface{}{"query": txt}
+	if len(c.model.SearchFields) > 0 {
+		match["fields"] = c.model.SearchFields
+	}
+	query := map[string]interface{}{
+		"from":  offset,
+		"size":  limit,
+		"query": map[string]interface{}{"multi_match": match},
+	}
+	body := &bytes.Buffer{}
+	err = json.NewEncoder(body).Encode(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.search(ctx, body, cfg)
+}
+
+// search sends the search request body and returns the hits with their _id set to the documents
+func (c *Client[T]) search(ctx context.Context, body io.Reader, cfg ListOptions) ([]Hit[T], error) {
+	var result Hits[T]
+	err := c.doRequest(ctx, "GET", fmt.Sprintf("%s/_search", c.typePath), body, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	op := currentOperation(ctx)
+	op.Took = time.Duration(result.Took) * time.Millisecond
+	op.Hits = len(result.Hits.Hits)
+	if cfg.total != nil {
+		*cfg.total = uint32(result.Hits.Total)
+	}
+	err = c.setIDs(result.Hits.Hits)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hits.Hits, nil
+}
+
+// setIDs sets the _id of the hits to their documents
+func (c *Client[T]) setIDs(hits []Hit[T]) error {
+	for n := range hits {
+		h := &hits[n]
+		err := c.model.SetID(&h.Source, h.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListOptions configures a request of DoListRequest or Search
+type ListOptions struct {
+	total *uint32
+}
+
+// ListOption is an option for DoListRequest and Search
+type ListOption func(*ListOptions)
+
+// WithTotal sets the total number of documents matching the request to t
+func WithTotal(t *uint32) ListOption {
+	return func(o *ListOptions) {
+		o.total = t
+	}
+}
+
+// Index creates a new document in elasticsearch
+// When the _id of the document is set, it updates the document. Documents without _id
+// are rejected, unless elasticsearch generates them, see Model.GeneratedIDs. For models
+// with Model.StoreID, the _id is generated and set to the document before it's sent
+// The first return value indicates, whether a new records has been created or not
+func (c *Client[T]) Index(ctx context.Context, m *T, opts ...IndexOption) (_ bool, err error) {
+	ctx, finish := c.operation(ctx, "Index")
+	defer func() { finish(err) }()
+	cfg := IndexConfig{Refresh: "false"}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	id, err := c.documentID(m)
+	if err != nil {
+		return false, err
+	}
+	b, err := c.encodeDocument(m, id)
+	if err != nil {
+		return false, err
+	}
+	body := bytes.NewBuffer(b)
+	if id != "" {
+		// without an ID, a retry might create the document twice
+		ctx = idempotent(ctx)
+	}
+	var response docResponse
+	err = c.doRequest(ctx, "POST", fmt.Sprintf("%s/%s?refresh=%s", c.typePath, url.PathEscape(id), cfg.Refresh), body, &response)
+	if err != nil {
+		return false, err
+	}
+	if response.Error != nil {
+		return false, response.Error
+	}
+	if response.ID == "" || (response.Result != "updated" && response.Result != "created") {
+		// if this case happens, please report with furhter information to hello@frederikvosberg.de to implement a better error handling
+		return false, errors.New("indexing of document in elasticsearch failed")
+	}
+	err = c.model.SetID(m, response.ID)
+	if err != nil {
+		return false, err
+	}
+	return response.Result == "created", nil
+}
+
+// IndexOption is an option for Index
+type IndexOption func(*IndexConfig)
+
+// IndexConfig configures a request of Index
+type IndexConfig struct {
+	Refresh string
+}
+
+// ForceRefresh forces the immediate refresh after indexing
+// it's used as an IndexOption param to Client.Index
+func ForceRefresh(cfg *IndexConfig) {
+	cfg.Refresh = "true"
+}
+
+// DeleteOneByID deletes the document with the given _id
+func (c *Client[T]) DeleteOneByID(ctx context.Context, id string) (err error) {
+	ctx, finish := c.operation(ctx, "DeleteOneByID")
+	defer func() { finish(err) }()
+	var response docResponse
+	err = c.doRequest(ctx, "DELETE", fmt.Sprintf("%s/%s", c.typePath, url.PathEscape(id)), nil, &response)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if response.Result == "not_found" {
+		return &Error{Status: http.StatusNotFound, Reason: fmt.Sprintf("%s with id %q not found", c.model.Type, id), Index: c.model.Index}
+	}
+	if response.Result != "deleted" {
+		// if this case happens, please report with furhter information to hello@frederikvosberg.de to implement a better error handling
+		return fmt.Errorf("deletion of document in elasticsearch failed, result was %q", response.Result)
+	}
+	return nil
+}
+
+// documentID returns the _id of the document to index. A document without _id gets a random
+// one for models with StoreID, otherwise it's returned empty to let elasticsearch generate it
+// It returns an error for a document without _id, when elasticsearch doesn't generate it. The
+// document would be written, before its generated _id is rejected by SetID
+func (c *Client[T]) documentID(doc *T) (string, error) {
+	id := c.model.ID(doc)
+	if id != "" {
+		return id, nil
+	}
+	if !c.model.GeneratedIDs {
+		return "", errors.Errorf("%s without ID, its ID can't be generated by elasticsearch", c.model.Type)
+	}
+	if !c.model.StoreID {
+		return "", nil
+	}
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	return id, c.model.SetID(doc, id)
+}
+
+// randomID returns a random _id, as long as those generated by elasticsearch
+func randomID() (string, error) {
+	b := make([]byte, 15)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't generate ID")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// encodeDocument encodes the document as JSON line, with the _id at IDPath for models with StoreID
+func (c *Client[T]) encodeDocument(doc *T, id string) ([]byte, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !c.model.StoreID || len(b) < 2 || b[0] != '{' {
+		return append(b, '\n'), nil
+	}
+	field, err := json.Marshal(map[string]string{c.model.IDPath: id})
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "{}" {
+		return append(field, '\n'), nil
+	}
+	// the generator chooses an IDPath, which isn't a field of the documents
+	field[len(field)-1] = ','
+	return append(append(field, b[1:]...), '\n'), nil
+}
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestIndexWithoutID(t *testing.T) {
+	tests := []struct {
+		name      string
+		generated bool // Model.GeneratedIDs
+		id        string
+		sent      bool // whether the document is sent to elasticsearch
+	}{
+		{"generated ID", true, "", true},
+		{"ID", false, "a", true},
+		{"ID which can't be generated", false, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if strings.HasSuffix(r.URL.Path, "/_bulk") {
+					fmt.Fprint(w, `{"items":[{"index":{"_id":"generated","status":201,"result":"created"}}]}`)
+					return
+				}
+				fmt.Fprint(w, `{"_id":"generated","result":"created"}`)
+			}))
+			c.model.GeneratedIDs = tt.generated
+
+			_, indexErr := c.Index(context.Background(), &testDoc{ID: tt.id})
+			_, bulkErr := c.Bulk(context.Background(), []BulkOp[testDoc]{IndexOp(&testDoc{ID: tt.id})})
+
+			for _, err := range []error{indexErr, bulkErr} {
+				if tt.sent != (err == nil) {
+					t.Errorf("expected an error %t, got %v", !tt.sent, err)
+				}
+			}
+			if want := map[bool]int{true: 2, false: 0}[tt.sent]; requests != want {
+				t.Errorf("expected %d requests, got %d", want, requests)
+			}
+		})
+	}
+}
+
+func TestDocumentIDEscaping(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		prefix string // path of the node URL
+		want   string // expected request URI of GetOneByID
+	}{
+		{"query", "x?refresh=true", "", "/docs/doc/x%3Frefresh=true"},
+		{"slash", "a/b", "", "/docs/doc/a%2Fb"},
+		{"escaped slash", "a%2Fb", "", "/docs/doc/a%252Fb"},
+		{"fragment", "1#frag", "", "/docs/doc/1%23frag"},
+		{"space", "a b", "", "/docs/doc/a%20b"},
+		{"node path", "a/b", "/es/", "/es/docs/doc/a%2Fb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uris []string
+			c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				uris = append(uris, r.Method+" "+r.RequestURI)
+				fmt.Fprintf(w, `{"_id":%q,"found":true,"result":"deleted"}`, tt.id)
+			}))
+			err := c.SetNodes(srv.URL + tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.GetOneByID(context.Background(), tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = c.DeleteOneByID(context.Background(), tt.id)
+			_, _ = c.Index(context.Background(), &testDoc{ID: tt.id})
+
+			want := []string{"GET " + tt.want, "DELETE " + tt.want, "POST " + tt.want + "?refresh=false"}
+			if strings.Join(uris, "\n") != strings.Join(want, "\n") {
+				t.Errorf("expected requests\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(uris, "\n"))
+			}
+		})
+	}
+}
+
+func TestStoreID(t *testing.T) {
+	tests := []struct {
+		name  string
+		store bool   // Model.StoreID
+		doc   string // name of the document
+		id    string // ID of the document, empty for a random one
+		want  string // expected body of the index request and the bulk request, with %s for the ID
+	}{
+		{"without stored ID", false, "a", "1", `{"name":"a"}`},
+		{"stored ID", true, "a", "1", `{"id":"1","name":"a"}`},
+		{"generated ID", true, "a", "", `{"id":"%s","name":"a"}`},
+		{"escaped ID", true, "a", `"1"`, `{"id":"\"1\"","name":"a"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				if strings.HasSuffix(r.URL.Path, "/_bulk") {
+					lines := strings.Split(string(b), "\n")
+					bodies = append(bodies, lines[1])
+					var meta map[string]struct {
+						ID string `json:"_id"`
+					}
+					_ = json.Unmarshal([]byte(lines[0]), &meta)
+					fmt.Fprintf(w, `{"items":[{"index":{"_id":%q,"status":201,"result":"created"}}]}`, meta["index"].ID)
+					return
+				}
+				bodies = append(bodies, strings.TrimSuffix(string(b), "\n"))
+				id, _ := url.PathUnescape(path.Base(r.URL.EscapedPath()))
+				fmt.Fprintf(w, `{"_id":%q,"result":"created"}`, id)
+			}))
+			c.model.IDPath = "id"
+			c.model.StoreID = tt.store
+			indexed := &testDoc{ID: tt.id, Name: tt.doc}
+			bulked := &testDoc{ID: tt.id, Name: tt.doc}
+
+			_, err := c.Index(context.Background(), indexed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.Bulk(context.Background(), []BulkOp[testDoc]{IndexOp(bulked)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for n, d := range []*testDoc{indexed, bulked} {
+				if tt.id != "" && d.ID != tt.id || tt.id == "" && len(d.ID) != 20 {
+					t.Errorf("expected the ID %q set to the document, got %q", tt.id, d.ID)
+				}
+				want := tt.want
+				if strings.Contains(want, "%s") {
+					want = fmt.Sprintf(want, d.ID)
+				}
+				if bodies[n] != want {
+					t.Errorf("expected body %s, got %s", want, bodies[n])
+				}
+			}
+			if tt.id == "" && indexed.ID == bulked.ID {
+				t.Errorf("expected different random IDs, got %s twice", indexed.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sentinels of the kinds of errors, which can be checked with errors.Is
+// like errors.Is(err, runtime.ErrNotFound)
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrIndexNotFound   = errors.New("index not found")
+	ErrVersionConflict = errors.New("version conflict")
+	ErrMappingConflict = errors.New("mapping conflict")
+	ErrUnavailable     = errors.New("elasticsearch unavailable")
+	ErrTimeout         = errors.New("timeout")
+)
+
+// Error is an error returned by elasticsearch. The errors of the clients can be
+// checked with errors.As for it and with errors.Is for the sentinels like ErrNotFound
+type Error struct {
+	Status    int     `json:"-"` // HTTP status code of the response
+	Type      string  `json:"type"`
+	Reason    string  `json:"reason"`
+	Index     string  `json:"index"`
+	CausedBy  *Error  `json:"caused_by"`
+	RootCause []Error `json:"root_cause"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("elasticsearch error %d", e.Status)
+	if e.Type != "" {
+		msg += " " + e.Type
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Index != "" && !strings.Contains(e.Reason, "["+e.Index+"]") {
+		msg += " (index " + e.Index + ")"
+	}
+	if e.CausedBy != nil && e.CausedBy.Reason != "" {
+		msg += ", caused by " + e.CausedBy.Type + ": " + e.CausedBy.Reason
+	}
+	return msg
+}
+
+// Is reports whether the error is of the kind of the sentinel target
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrIndexNotFound:
+		return e.hasType("index_not_found_exception")
+	case ErrVersionConflict:
+		return e.Status == http.StatusConflict || e.hasType("version_conflict_engine_exception")
+	case ErrMappingConflict:
+		return e.hasType("mapper_parsing_exception", "strict_dynamic_mapping_exception") ||
+			e.hasType("illegal_argument_exception") && strings.HasPrefix(e.Reason, "mapper [")
+	case ErrUnavailable:
+		return e.Status == http.StatusBadGateway || e.Status == http.StatusServiceUnavailable ||
+			e.hasType("unavailable_shards_exception", "no_shard_available_action_exception", "cluster_block_exception")
+	case ErrTimeout:
+		return e.Status == http.StatusRequestTimeout || e.Status == http.StatusGatewayTimeout ||
+			e.hasType("timeout_exception", "process_cluster_event_timeout_exception", "receive_timeout_transport_exception")
+	}
+	return false
+}
+
+// hasType returns whether the error, its causes or root causes are of one of the types
+func (e *Error) hasType(types ...string) bool {
+	for _, t := range types {
+		if e.Type == t {
+			return true
+		}
+	}
+	for n := range e.RootCause {
+		if e.RootCause[n].hasType(types...) {
+			return true
+		}
+	}
+	return e.CausedBy != nil && e.CausedBy.hasType(types...)
+}
+
+// timeoutError is returned for requests, which timed out before elasticsearch responded
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string {
+	return e.err.Error()
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
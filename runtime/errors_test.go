@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrorIs(t *testing.T) {
+	sentinels := []error{ErrNotFound, ErrIndexNotFound, ErrVersionConflict, ErrMappingConflict, ErrUnavailable, ErrTimeout}
+	tests := []struct {
+		name string
+		err  error
+		is   []error // sentinels the error is, it isn't any other
+	}{
+		{"document not found", &Error{Status: http.StatusNotFound}, []error{ErrNotFound}},
+		{"index not found", &Error{Status: http.StatusNotFound, Type: "index_not_found_exception"}, []error{ErrNotFound, ErrIndexNotFound}},
+		{"index not found as root cause", &Error{Status: http.StatusBadRequest, Type: "search_phase_execution_exception", RootCause: []Error{{Type: "index_not_found_exception"}}}, []error{ErrIndexNotFound}},
+		{"conflict status", &Error{Status: http.StatusConflict}, []error{ErrVersionConflict}},
+		{"version conflict in bulk item", &Error{Status: http.StatusOK, Type: "version_conflict_engine_exception"}, []error{ErrVersionConflict}},
+		{"mapper parsing", &Error{Status: http.StatusBadRequest, Type: "mapper_parsing_exception"}, []error{ErrMappingConflict}},
+		{"strict dynamic mapping as cause", &Error{Status: http.StatusBadRequest, Type: "illegal_argument_exception", CausedBy: &Error{Type: "strict_dynamic_mapping_exception"}}, []error{ErrMappingConflict}},
+		{"conflicting mapper", &Error{Status: http.StatusBadRequest, Type: "illegal_argument_exception", Reason: "mapper [name] cannot be changed from type [text] to [keyword]"}, []error{ErrMappingConflict}},
+		{"other illegal argument", &Error{Status: http.StatusBadRequest, Type: "illegal_argument_exception", Reason: "unknown setting"}, nil},
+		{"bad gateway", &Error{Status: http.StatusBadGateway}, []error{ErrUnavailable}},
+		{"service unavailable", &Error{Status: http.StatusServiceUnavailable}, []error{ErrUnavailable}},
+		{"unavailable shards", &Error{Status: http.StatusInternalServerError, Type: "unavailable_shards_exception"}, []error{ErrUnavailable}},
+		{"no shard available", &Error{Status: http.StatusInternalServerError, Type: "no_shard_available_action_exception"}, []error{ErrUnavailable}},
+		{"write block", &Error{Status: http.StatusForbidden, Type: "cluster_block_exception"}, []error{ErrUnavailable}},
+		{"request timeout", &Error{Status: http.StatusRequestTimeout}, []error{ErrTimeout}},
+		{"gateway timeout", &Error{Status: http.StatusGatewayTimeout}, []error{ErrTimeout}},
+		{"timeout exception", &Error{Status: http.StatusInternalServerError, Type: "timeout_exception"}, []error{ErrTimeout}},
+		{"cluster event timeout", &Error{Status: http.StatusServiceUnavailable, Type: "process_cluster_event_timeout_exception"}, []error{ErrUnavailable, ErrTimeout}},
+		{"transport timeout as cause", &Error{Status: http.StatusInternalServerError, CausedBy: &Error{Type: "receive_timeout_transport_exception"}}, []error{ErrTimeout}},
+		{"internal error", &Error{Status: http.StatusInternalServerError, Type: "exception"}, nil},
+		{"wrapped", errors.Wrap(&Error{Status: http.StatusNotFound}, "getting document"), []error{ErrNotFound}},
+		{"in bulk error", &BulkError{Items: []BulkItem{{Status: http.StatusCreated}, {Status: http.StatusConflict, Error: &Error{Status: http.StatusConflict, Type: "version_conflict_engine_exception"}}}}, []error{ErrVersionConflict}},
+		{"request timed out", &timeoutError{context.DeadlineExceeded}, []error{ErrTimeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range sentinels {
+				want := contains(tt.is, s)
+				if errors.Is(tt.err, s) != want {
+					t.Errorf("expected errors.Is(err, %q) to be %v", s, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnsureExistingIndex creates the index, if it doesn't exist
+func (c *Client[T]) EnsureExistingIndex(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "EnsureExistingIndex")
+	defer func() { finish(err) }()
+	indexExists, err := c.IndexExists(ctx)
+	if err != nil {
+		return err
+	}
+	if indexExists {
+		return nil
+	}
+	return c.CreateIndex(ctx)
+}
+
+// RecreateIndex deletes the index and creates it again
+// A versioned client points the alias to a new empty index version instead, see Reindex
+func (c *Client[T]) RecreateIndex(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "RecreateIndex")
+	defer func() { finish(err) }()
+	if c.versioned {
+		return c.emptyIndexVersion(ctx)
+	}
+	_, err = c.DeleteIndex(ctx)
+	if err != nil {
+		return err
+	}
+	return c.CreateIndex(ctx)
+}
+
+// IndexExists checks whether the index exists
+func (c *Client[T]) IndexExists(ctx context.Context) (_ bool, err error) {
+	ctx, finish := c.operation(ctx, "IndexExists")
+	defer func() { finish(err) }()
+	req, err := c.newRequest(ctx, "HEAD", c.indexPath, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.send(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	currentOperation(ctx).Status = res.StatusCode
+	if res.StatusCode != 200 && res.StatusCode != 404 {
+		return false, &Error{Status: res.StatusCode, Reason: "checking existence of index failed", Index: c.model.Index}
+	}
+	return res.StatusCode == 200, nil
+}
+
+// DeleteIndex deletes the index, or all index versions of a versioned client
+// The return value indicates, whether the deletion has been acknowledged
+func (c *Client[T]) DeleteIndex(ctx context.Context) (_ bool, err error) {
+	ctx, finish := c.operation(ctx, "DeleteIndex")
+	defer func() { finish(err) }()
+	path := c.indexPath
+	if c.versioned {
+		versions, err := c.IndexVersions(ctx)
+		if err != nil {
+			return false, err
+		}
+		if len(versions) == 0 {
+			return false, nil
+		}
+		names := make([]string, len(versions))
+		for i, v := range versions {
+			names[i] = v.Name
+		}
+		path = "/" + strings.Join(names, ",")
+	}
+	var response indexManipulationResponse
+	err = c.doRequest(ctx, "DELETE", path, nil, &response)
+	if errors.Is(err, ErrIndexNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return response.Acknowledged, nil
+}
+
+// CreateIndex creates the index with the IndexDefinition of the model
+// A versioned client creates the first index version and points the alias to it
+func (c *Client[T]) CreateIndex(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "CreateIndex")
+	defer func() { finish(err) }()
+	if c.versioned {
+		exists, err := c.IndexExists(ctx)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.Errorf("index %s already exists", c.model.Index)
+		}
+		return c.emptyIndexVersion(ctx)
+	}
+	var response indexManipulationResponse
+	err = c.doRequest(ctx, "PUT", c.indexPath, strings.NewReader(c.model.IndexDefinition), &response)
+	if err != nil {
+		return err
+	}
+	if !response.Acknowledged {
+		return errors.Errorf("creation of index %s not acknowledged", c.model.Index)
+	}
+	return nil
+}
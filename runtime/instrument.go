@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Operation is an operation of a Client, like GetOneByID, reported to the Instrumentation
+type Operation struct {
+	Name      string        // Name of the method of the Client, like GetOneByID
+	Index     string        // Name of the index
+	Start     time.Time     // Start of the operation
+	Duration  time.Duration // Duration of the operation, set when it's finished
+	Status    int           // HTTP status code of the last response, 0 if there is none
+	Err       error         // Error of the operation
+	ErrorType string        // Type of the error, like index_not_found_exception, see ErrorType
+	Took      time.Duration // Time elasticsearch took for a search or bulk request
+	Hits      int           // Number of documents returned by a search or sent with a bulk request
+}
+
+// Instrumentation is notified about every operation of the Client, to record metrics or traces
+// Operations called by another operation, like GetList calling DoListRequest, are reported as part of it
+type Instrumentation interface {
+	// OperationStarted is called at the start of the operation, the returned context is used by the operation
+	OperationStarted(ctx context.Context, op *Operation) context.Context
+	// OperationFinished is called with the context returned by OperationStarted
+	OperationFinished(ctx context.Context, op *Operation)
+}
+
+// Instrument reports the operations of the client to the instrumentations
+func (c *Client[T]) Instrument(in ...Instrumentation) {
+	c.instrumentation = append(c.instrumentation[:len(c.instrumentation):len(c.instrumentation)], in...)
+}
+
+type operationKey struct{}
+
+// operation starts the operation. The returned function finishes it with its error
+//
+//	ctx, finish := c.operation(ctx, "GetOneByID")
+//	defer func() { finish(err) }()
+func (c *Client[T]) operation(ctx context.Context, name string) (context.Context, func(error)) {
+	if len(c.instrumentation) == 0 || ctx.Value(operationKey{}) != nil {
+		return ctx, func(error) {}
+	}
+	op := &Operation{Name: name, Index: c.model.Index, Start: time.Now()}
+	ctx = context.WithValue(ctx, operationKey{}, op)
+	ctxs := make([]context.Context, len(c.instrumentation))
+	for i, in := range c.instrumentation {
+		ctx = in.OperationStarted(ctx, op)
+		ctxs[i] = ctx
+	}
+	return ctx, func(err error) {
+		op.Duration = time.Since(op.Start)
+		op.Err = err
+		op.ErrorType = ErrorType(err)
+		for i := len(c.instrumentation) - 1; i >= 0; i-- {
+			c.instrumentation[i].OperationFinished(ctxs[i], op)
+		}
+	}
+}
+
+// currentOperation returns the operation of the context, to record the results of requests
+func currentOperation(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	if op == nil {
+		return &Operation{}
+	}
+	return op
+}
+
+// ErrorType returns a short type of the error for metrics: the type of an *Error, or
+// timeout, bulk or other. It returns an empty string for nil
+func ErrorType(err error) string {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) && e.Type != "" {
+		return e.Type
+	}
+	var bulk *BulkError
+	switch {
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.As(err, &bulk):
+		return "bulk"
+	case e != nil:
+		return "http_error"
+	}
+	return "other"
+}
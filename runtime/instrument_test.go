@@ -0,0 +1,151 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// recorder is an Instrumentation, which records the finished operations
+type recorder struct {
+	mu  sync.Mutex
+	ops []Operation
+}
+
+func (r *recorder) OperationStarted(ctx context.Context, op *Operation) context.Context {
+	return ctx
+}
+
+func (r *recorder) OperationFinished(ctx context.Context, op *Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, *op)
+}
+
+func TestInstrument(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		call   func(ctx context.Context, c *Client[testDoc]) error
+		want   Operation
+	}{
+		{
+			name:   "search",
+			status: http.StatusOK,
+			body:   `{"took":7,"hits":{"total":2,"hits":[{"_id":"a","_source":{}},{"_id":"b","_source":{}}]}}`,
+			call: func(ctx context.Context, c *Client[testDoc]) error {
+				_, err := c.GetList(ctx, 0, 10)
+				return err
+			},
+			want: Operation{Name: "GetList", Index: "docs", Status: http.StatusOK, Took: 7 * time.Millisecond, Hits: 2},
+		},
+		{
+			name:   "elasticsearch error",
+			status: http.StatusNotFound,
+			body:   `{"error":{"type":"index_not_found_exception","reason":"no such index [docs]","index":"docs"},"status":404}`,
+			call: func(ctx context.Context, c *Client[testDoc]) error {
+				_, err := c.GetOneByID(ctx, "a")
+				return err
+			},
+			want: Operation{Name: "GetOneByID", Index: "docs", Status: http.StatusNotFound, ErrorType: "index_not_found_exception"},
+		},
+		{
+			name:   "document not found",
+			status: http.StatusOK,
+			body:   `{"_id":"a","found":false}`,
+			call: func(ctx context.Context, c *Client[testDoc]) error {
+				_, err := c.GetOneByID(ctx, "a")
+				return err
+			},
+			want: Operation{Name: "GetOneByID", Index: "docs", Status: http.StatusOK, ErrorType: "not_found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			rec := &recorder{}
+			c.Instrument(rec)
+
+			err := tt.call(context.Background(), c)
+
+			if len(rec.ops) != 1 {
+				t.Fatalf("expected 1 operation, nested ones are part of it, got %+v", rec.ops)
+			}
+			op := rec.ops[0]
+			if op.Err != err {
+				t.Errorf("expected the error %v of the call, got %v", err, op.Err)
+			}
+			if op.Start.IsZero() || op.Duration <= 0 {
+				t.Errorf("expected the start and duration to be set, got %s and %s", op.Start, op.Duration)
+			}
+			op.Start, op.Duration, op.Err = time.Time{}, 0, nil
+			if op != tt.want {
+				t.Errorf("expected operation %+v, got %+v", tt.want, op)
+			}
+		})
+	}
+}
+
+func TestInstrumentOrder(t *testing.T) {
+	var calls []string
+	c, _ := newTestClient(t, http.NotFoundHandler())
+	for _, name := range []string{"a", "b"} {
+		c.Instrument(orderInstrumentation{name: name, calls: &calls})
+	}
+
+	_, _ = c.IndexExists(context.Background())
+
+	want := "[started a started b finished b finished a]"
+	if got := fmt.Sprint(calls); got != want {
+		t.Errorf("expected the calls %s, got %s", want, got)
+	}
+}
+
+// orderInstrumentation records the calls of the instrumentation
+type orderInstrumentation struct {
+	name  string
+	calls *[]string
+}
+
+func (o orderInstrumentation) OperationStarted(ctx context.Context, op *Operation) context.Context {
+	*o.calls = append(*o.calls, "started "+o.name)
+	return ctx
+}
+
+func (o orderInstrumentation) OperationFinished(ctx context.Context, op *Operation) {
+	*o.calls = append(*o.calls, "finished "+o.name)
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"no error", nil, ""},
+		{"elasticsearch error", errors.Wrap(&Error{Status: 400, Type: "parsing_exception"}, "search failed"), "parsing_exception"},
+		{"timeout", &timeoutError{err: context.DeadlineExceeded}, "timeout"},
+		{"not found", &Error{Status: http.StatusNotFound}, "not_found"},
+		{"bulk", &BulkError{}, "bulk"},
+		{"HTTP error", &Error{Status: http.StatusBadRequest}, "http_error"},
+		{"other", errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorType(tt.err); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ScrollConfig configures the scroll of All and Each
+type ScrollConfig struct {
+	Size      int           // Number of documents fetched per request
+	KeepAlive time.Duration // How long elasticsearch keeps the scroll context between two requests
+}
+
+// ScrollOption is an option for All and Each
+type ScrollOption func(*ScrollConfig)
+
+// WithScrollSize fetches n documents per request
+func WithScrollSize(n int) ScrollOption {
+	return func(cfg *ScrollConfig) {
+		cfg.Size = n
+	}
+}
+
+// WithScrollKeepAlive keeps the scroll context alive for d between two requests
+func WithScrollKeepAlive(d time.Duration) ScrollOption {
+	return func(cfg *ScrollConfig) {
+		cfg.KeepAlive = d
+	}
+}
+
+// Iterator iterates over all documents matching a query with the scroll API, see All
+// The pages are fetched lazily, while the iterator is advanced
+//
+//	it := c.All(ctx, q)
+//	defer it.Close()
+//	for it.Next() {
+//		doc := it.Value()
+//	}
+//	if it.Err() != nil {
+type Iterator[T any] struct {
+	c         *Client[T]
+	ctx       context.Context
+	body      map[string]interface{}
+	keepAlive string
+	scrollID  string
+	hits      []Hit[T]
+	pos       int
+	started   bool
+	done      bool
+	err       error
+}
+
+// All returns an iterator over all documents matching the query, a nil query matches
+// all documents. From and Size of the query are ignored, see WithScrollSize
+// The iterator has to be closed, when it's not iterated until the end
+func (c *Client[T]) All(ctx context.Context, q *Query, opts ...ScrollOption) *Iterator[T] {
+	cfg := ScrollConfig{Size: 1000, KeepAlive: time.Minute}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	body := q.body()
+	delete(body, "from")
+	body["size"] = cfg.Size
+	if _, ok := body["sort"]; !ok {
+		body["sort"] = []string{"_doc"}
+	}
+	return &Iterator[T]{
+		c:         c,
+		ctx:       ctx,
+		body:      body,
+		keepAlive: fmt.Sprintf("%dms", cfg.KeepAlive.Milliseconds()),
+		pos:       -1,
+	}
+}
+
+// Each calls f for every document matching the query, until f returns an error, see All
+func (c *Client[T]) Each(ctx context.Context, q *Query, f func(*T) error, opts ...ScrollOption) (err error) {
+	ctx, finish := c.operation(ctx, "Each")
+	defer func() { finish(err) }()
+	it := c.All(ctx, q, opts...)
+	defer it.Close()
+	for it.Next() {
+		err := f(it.Value())
+		if err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Next advances the iterator to the next document, fetching the next page if needed
+// It returns false, when there are no more documents or an error occurred, see Err
+func (it *Iterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	it.pos++
+	if it.pos < len(it.hits) {
+		return true
+	}
+	err := it.fetch()
+	if err != nil {
+		it.err = err
+		it.Close()
+		return false
+	}
+	if len(it.hits) == 0 {
+		it.err = it.Close()
+		return false
+	}
+	it.pos = 0
+	return true
+}
+
+// Value returns the current document
+func (it *Iterator[T]) Value() *T {
+	return &it.hits[it.pos].Source
+}
+
+// Err returns the error, which stopped the iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and clears the scroll context in elasticsearch
+func (it *Iterator[T]) Close() (err error) {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	it.hits = nil
+	if it.scrollID == "" {
+		return nil
+	}
+	// the context of the iteration might be canceled already, which shouldn't leave the scroll context behind
+	ctx, finish := it.c.operation(context.Background(), "ClearScroll")
+	defer func() { finish(err) }()
+	body, err := json.Marshal(map[string]interface{}{"scroll_id": []string{it.scrollID}})
+	if err != nil {
+		return err
+	}
+	var response struct {
+		Succeeded bool   `json:"succeeded"`
+		Error     *Error `json:"error"`
+	}
+	err = it.c.doRequest(ctx, "DELETE", "/_search/scroll", bytes.NewReader(body), &response)
+	if err != nil {
+		return errors.Wrap(err, "couldn't clear scroll")
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return nil
+}
+
+// fetch fetches the next page
+func (it *Iterator[T]) fetch() (err error) {
+	ctx, finish := it.c.operation(it.ctx, "Scroll")
+	defer func() { finish(err) }()
+	var (
+		url  string
+		body interface{}
+	)
+	if !it.started {
+		it.started = true
+		url = fmt.Sprintf("%s/_search?scroll=%s", it.c.typePath, it.keepAlive)
+		body = it.body
+		ctx = idempotent(ctx)
+	} else {
+		// a retry of a scroll request might skip a page, so it's not retried
+		url = "/_search/scroll"
+		body = map[string]interface{}{"scroll": it.keepAlive, "scroll_id": it.scrollID}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Hits[T]
+		ScrollID string `json:"_scroll_id"`
+	}
+	err = it.c.doRequest(ctx, "POST", url, bytes.NewReader(b), &result)
+	if err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.ScrollID != "" {
+		it.scrollID = result.ScrollID
+	}
+	it.hits = result.Hits.Hits.Hits
+	op := currentOperation(ctx)
+	op.Took = time.Duration(result.Took) * time.Millisecond
+	op.Hits = len(it.hits)
+	return it.c.setIDs(it.hits)
+}
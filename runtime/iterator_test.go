@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// scrollServer is a fake of the scroll API over its documents
+type scrollServer struct {
+	mu        sync.Mutex
+	docs      []string // IDs of the documents
+	fail      bool     // whether the scroll requests after the first page fail
+	size      int      // page size of the search request
+	pos       int      // position of the next page
+	scrolls   int      // number of scroll requests after the search request
+	keepAlive string   // keep alive of the search request
+	cleared   []string // cleared scroll IDs
+}
+
+func (s *scrollServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body struct {
+		Size     int      `json:"size"`
+		ScrollID []string `json:"scroll_id"`
+	}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/docs/doc/_search":
+		s.keepAlive = r.URL.Query().Get("scroll")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.size = body.Size
+	case r.Method == "POST" && r.URL.Path == "/_search/scroll":
+		s.scrolls++
+		if s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"type":"search_context_missing_exception","reason":"no search context"},"status":500}`)
+			return
+		}
+	case r.Method == "DELETE" && r.URL.Path == "/_search/scroll":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.cleared = append(s.cleared, body.ScrollID...)
+		fmt.Fprint(w, `{"succeeded":true}`)
+		return
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	end := s.pos + s.size
+	if end > len(s.docs) {
+		end = len(s.docs)
+	}
+	hits := make([]string, 0, end-s.pos)
+	for _, id := range s.docs[s.pos:end] {
+		hits = append(hits, fmt.Sprintf(`{"_id":%q,"_source":{"name":"doc %s"}}`, id, id))
+	}
+	s.pos = end
+	fmt.Fprintf(w, `{"_scroll_id":"scroll","took":1,"hits":{"total":%d,"hits":[%s]}}`, len(s.docs), strings.Join(hits, ","))
+}
+
+func TestAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		docs    []string
+		fail    bool
+		want    string // IDs of the iterated documents
+		scrolls int
+		err     bool
+	}{
+		{"multiple pages", []string{"a", "b", "c", "d", "e"}, false, "a,b,c,d,e", 3, false},
+		{"full pages", []string{"a", "b", "c", "d"}, false, "a,b,c,d", 2, false},
+		{"no documents", nil, false, "", 0, false},
+		{"failing scroll isn't retried", []string{"a", "b", "c"}, true, "a,b", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &scrollServer{docs: tt.docs, fail: tt.fail}
+			c, _ := newTestClient(t, srv)
+
+			it := c.All(context.Background(), nil, WithScrollSize(2))
+			var ids []string
+			for it.Next() {
+				d := it.Value()
+				if d.Name != "doc "+d.ID {
+					t.Errorf("expected the _id to be set to document %s", d.Name)
+				}
+				ids = append(ids, d.ID)
+			}
+
+			if tt.err != (it.Err() != nil) {
+				t.Fatalf("expected an error %t, got %v", tt.err, it.Err())
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("expected documents %s, got %s", tt.want, got)
+			}
+			if srv.size != 2 || srv.keepAlive != "60000ms" {
+				t.Errorf("expected size 2 and keep alive 60000ms, got %d and %s", srv.size, srv.keepAlive)
+			}
+			if srv.scrolls != tt.scrolls {
+				t.Errorf("expected %d scroll requests, got %d", tt.scrolls, srv.scrolls)
+			}
+			if len(srv.cleared) != 1 || srv.cleared[0] != "scroll" {
+				t.Errorf("expected the scroll to be cleared once, got %v", srv.cleared)
+			}
+			if it.Next() || it.Close() != nil || len(srv.cleared) != 1 {
+				t.Error("expected the finished iterator to stay closed")
+			}
+		})
+	}
+}
+
+func TestEach(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		name string
+		stop string // ID of the document, for which f returns an error
+		want string
+		err  error
+	}{
+		{"all documents", "", "a,b,c", nil},
+		{"stopped by f", "b", "a,b", errStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &scrollServer{docs: []string{"a", "b", "c"}}
+			c, _ := newTestClient(t, srv)
+			var ids []string
+
+			err := c.Each(context.Background(), nil, func(d *testDoc) error {
+				ids = append(ids, d.ID)
+				if d.ID == tt.stop {
+					return errStop
+				}
+				return nil
+			}, WithScrollSize(2))
+
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("expected documents %s, got %s", tt.want, got)
+			}
+			if len(srv.cleared) != 1 {
+				t.Errorf("expected the scroll to be cleared once, got %v", srv.cleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Doer sends a request to elasticsearch, like *http.Client
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// DoerFunc is a function implementing Doer
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+// Do calls f
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the sending of every request, including retries, see Use
+type Middleware func(next Doer) Doer
+
+// Use adds middlewares, which are called with every request sent to a node in the order they are added
+// The requests already carry the headers, like Authorization, which should be redacted when they are logged
+func (c *Client[T]) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], mw...)
+}
+
+// doer returns the http client wrapped by the middlewares
+func (c *Client[T]) doer() Doer {
+	var d Doer = c.http
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	return d
+}
+
+// redacted replaces redacted values in logs
+const redacted = "[REDACTED]"
+
+// LogConfig configures the logging of Logging and SlowRequests
+type LogConfig struct {
+	Headers       bool     // Whether the headers are logged
+	Bodies        bool     // Whether the bodies are logged
+	MaxBody       int      // Maximum number of logged bytes of a body
+	RedactHeaders []string // Headers, whose values are redacted, Authorization and Cookie are always redacted
+	RedactFields  []string // Fields of JSON bodies, whose values are redacted, in any depth
+}
+
+// LogOption is an option for Logging and SlowRequests
+type LogOption func(*LogConfig)
+
+// LogHeaders logs the headers of the requests and responses
+func LogHeaders(cfg *LogConfig) {
+	cfg.Headers = true
+}
+
+// LogBodies logs up to max bytes of the bodies of the requests and responses
+func LogBodies(max int) LogOption {
+	return func(cfg *LogConfig) {
+		cfg.Bodies = true
+		cfg.MaxBody = max
+	}
+}
+
+// RedactHeaders redacts the values of the headers in the logs
+func RedactHeaders(names ...string) LogOption {
+	return func(cfg *LogConfig) {
+		cfg.RedactHeaders = append(cfg.RedactHeaders, names...)
+	}
+}
+
+// RedactFields redacts the values of the fields of JSON bodies in the logs, like "password"
+func RedactFields(names ...string) LogOption {
+	return func(cfg *LogConfig) {
+		cfg.RedactFields = append(cfg.RedactFields, names...)
+	}
+}
+
+// Logging logs every request with its method, URL, status and duration
+// Failed requests are logged as error, all others as debug
+func Logging(logger *slog.Logger, opts ...LogOption) Middleware {
+	return logRequests(logger, 0, opts)
+}
+
+// SlowRequests logs the requests, which took longer than threshold, as warning
+func SlowRequests(logger *slog.Logger, threshold time.Duration, opts ...LogOption) Middleware {
+	return logRequests(logger, threshold, opts)
+}
+
+func logRequests(logger *slog.Logger, threshold time.Duration, opts []LogOption) Middleware {
+	cfg := LogConfig{RedactHeaders: []string{"Authorization", "Cookie", "Set-Cookie"}}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			var reqBody []byte
+			if cfg.Bodies && req.GetBody != nil {
+				if b, err := req.GetBody(); err == nil {
+					reqBody, _ = io.ReadAll(b)
+					b.Close()
+				}
+			}
+			res, err := next.Do(req)
+			took := time.Since(start)
+			if took < threshold {
+				return res, err
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Duration("took", took),
+			}
+			if cfg.Headers {
+				attrs = append(attrs, slog.Any("request_headers", cfg.headers(req.Header)))
+			}
+			if cfg.Bodies && reqBody != nil {
+				attrs = append(attrs, slog.String("request_body", cfg.body(reqBody)))
+			}
+			level, msg := slog.LevelDebug, "elasticsearch request"
+			if threshold > 0 {
+				level, msg = slog.LevelWarn, "slow elasticsearch request"
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(req.Context(), slog.LevelError, "elasticsearch request failed", attrs...)
+				return res, err
+			}
+			attrs = append(attrs, slog.Int("status", res.StatusCode))
+			if cfg.Headers {
+				attrs = append(attrs, slog.Any("response_headers", cfg.headers(res.Header)))
+			}
+			if cfg.Bodies {
+				resBody, readErr := io.ReadAll(res.Body)
+				res.Body.Close()
+				res.Body = io.NopCloser(bytes.NewReader(resBody))
+				if readErr == nil {
+					attrs = append(attrs, slog.String("response_body", cfg.body(resBody)))
+				}
+			}
+			if res.StatusCode >= 400 && level < slog.LevelError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(req.Context(), level, msg, attrs...)
+			return res, nil
+		})
+	}
+}
+
+// headers returns the headers with the redacted values replaced
+func (cfg LogConfig) headers(h http.Header) map[string]string {
+	res := make(map[string]string, len(h))
+	for name, values := range h {
+		res[name] = strings.Join(values, ", ")
+		for _, r := range cfg.RedactHeaders {
+			if strings.EqualFold(name, r) {
+				res[name] = redacted
+			}
+		}
+	}
+	return res
+}
+
+// body returns the body with the values of the redacted fields replaced, truncated to MaxBody bytes
+func (cfg LogConfig) body(b []byte) string {
+	if len(cfg.RedactFields) > 0 {
+		b = redactJSON(b, cfg.RedactFields)
+	}
+	if cfg.MaxBody > 0 && len(b) > cfg.MaxBody {
+		return string(b[:cfg.MaxBody]) + "..."
+	}
+	return string(b)
+}
+
+// redactJSON replaces the values of the fields in the JSON documents of b, which may be NDJSON
+// Bodies which can't be decoded are redacted completely, not to leak the fields
+func redactJSON(b []byte, fields []string) []byte {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out bytes.Buffer
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return out.Bytes()
+		}
+		if err != nil {
+			return []byte(redacted)
+		}
+		enc, err := json.Marshal(redactValue(v, fields))
+		if err != nil {
+			return []byte(redacted)
+		}
+		out.Write(enc)
+		out.WriteByte('\n')
+	}
+}
+
+func redactValue(v interface{}, fields []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if contains(fields, k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(e, fields)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(e, fields)
+		}
+	}
+	return v
+}
@@ -0,0 +1,233 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logRecorder captures the records of a slog.Logger
+type logRecorder struct {
+	buf bytes.Buffer
+}
+
+func (r *logRecorder) logger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(&r.buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// records returns the logged records decoded from JSON
+func (r *logRecorder) records(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&r.buf)
+	for dec.More() {
+		var rec map[string]interface{}
+		err := dec.Decode(&rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []LogOption
+		status int
+		want   map[string]interface{} // expected attributes, nested maps are compared by their given keys
+		absent []string               // attributes, which mustn't be logged
+		leaked []string               // strings, which mustn't be logged at all
+	}{
+		{
+			name:   "without headers and bodies",
+			status: http.StatusCreated,
+			want: map[string]interface{}{
+				"level":  "DEBUG",
+				"msg":    "elasticsearch request",
+				"method": "POST",
+				"status": float64(201),
+			},
+			absent: []string{"request_headers", "response_headers", "request_body", "response_body"},
+			leaked: []string{"secret"},
+		},
+		{
+			name:   "redacted headers",
+			opts:   []LogOption{LogHeaders, RedactHeaders("x-api-secret")},
+			status: http.StatusCreated,
+			want: map[string]interface{}{
+				"request_headers": map[string]interface{}{
+					"Authorization": redacted,
+					"X-Api-Secret":  redacted,
+					"Content-Type":  "application/json; charset=utf-8",
+				},
+				"response_headers": map[string]interface{}{
+					"Set-Cookie":   redacted,
+					"X-Elastic-Id": "node-1",
+				},
+			},
+			leaked: []string{"c2VjcmV0", "session=", "secret-value"},
+		},
+		{
+			name:   "redacted fields",
+			opts:   []LogOption{LogBodies(0), RedactFields("name", "token")},
+			status: http.StatusCreated,
+			want: map[string]interface{}{
+				"request_body":  `{"name":"[REDACTED]"}` + "\n",
+				"response_body": `{"_id":"1","result":"created","token":"[REDACTED]"}` + "\n",
+			},
+			leaked: []string{"secret"},
+		},
+		{
+			name:   "truncated bodies",
+			opts:   []LogOption{LogBodies(8)},
+			status: http.StatusCreated,
+			want: map[string]interface{}{
+				"request_body":  `{"name":...`,
+				"response_body": `{"_id":"...`,
+			},
+		},
+		{
+			name:   "failed request",
+			status: http.StatusBadRequest,
+			want: map[string]interface{}{
+				"level":  "ERROR",
+				"msg":    "elasticsearch request",
+				"status": float64(400),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Set-Cookie", "session=secret")
+				w.Header().Set("X-Elastic-Id", "node-1")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"_id":"1","result":"created","token":"secret"}`)
+			}))
+			c.SetBasicAuth("elastic", "secret")
+			var logs logRecorder
+			c.Use(func(next Doer) Doer {
+				return DoerFunc(func(req *http.Request) (*http.Response, error) {
+					req.Header.Set("X-Api-Secret", "secret-value")
+					return next.Do(req)
+				})
+			}, Logging(logs.logger(), tt.opts...))
+
+			_, _ = c.Index(context.Background(), &testDoc{ID: "1", Name: "secret"})
+
+			records := logs.records(t)
+			if len(records) != 1 {
+				t.Fatalf("expected 1 log record, got %d: %v", len(records), records)
+			}
+			rec := records[0]
+			assertAttrs(t, "", rec, tt.want)
+			for _, a := range tt.absent {
+				if _, ok := rec[a]; ok {
+					t.Errorf("expected no %s, got %v", a, rec[a])
+				}
+			}
+			logged, _ := json.Marshal(rec)
+			for _, l := range tt.leaked {
+				if strings.Contains(string(logged), l) {
+					t.Errorf("expected %q not to be logged, got %s", l, logged)
+				}
+			}
+		})
+	}
+}
+
+// assertAttrs compares the attributes of want with those of got, nested maps by their keys in want
+func assertAttrs(t *testing.T, prefix string, got, want map[string]interface{}) {
+	t.Helper()
+	for k, w := range want {
+		if wm, ok := w.(map[string]interface{}); ok {
+			gm, ok := got[k].(map[string]interface{})
+			if !ok {
+				t.Errorf("expected %s%s to be an object, got %v", prefix, k, got[k])
+				continue
+			}
+			assertAttrs(t, prefix+k+".", gm, wm)
+			continue
+		}
+		if got[k] != w {
+			t.Errorf("expected %s%s %v, got %v", prefix, k, w, got[k])
+		}
+	}
+}
+
+func TestSlowRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		delay  time.Duration
+		status int
+		level  string // expected level, empty if the request isn't logged
+	}{
+		{"fast", 0, http.StatusOK, ""},
+		{"slow", 50 * time.Millisecond, http.StatusOK, "WARN"},
+		{"slow and failed", 50 * time.Millisecond, http.StatusBadRequest, "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.delay)
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"_id":"1","found":true,"_source":{}}`)
+			}))
+			var logs logRecorder
+			c.Use(SlowRequests(logs.logger(), 25*time.Millisecond))
+
+			_, _ = c.GetOneByID(context.Background(), "1")
+
+			records := logs.records(t)
+			if tt.level == "" {
+				if len(records) != 0 {
+					t.Fatalf("expected no log records, got %v", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("expected 1 log record, got %d: %v", len(records), records)
+			}
+			assertAttrs(t, "", records[0], map[string]interface{}{
+				"level":  tt.level,
+				"msg":    "slow elasticsearch request",
+				"method": "GET",
+				"status": float64(tt.status),
+			})
+			if took, _ := records[0]["took"].(float64); time.Duration(took) < 25*time.Millisecond {
+				t.Errorf("expected took of at least 25ms, got %v", records[0]["took"])
+			}
+		})
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"top level", `{"password":"secret","user":"elastic"}`, `{"password":"[REDACTED]","user":"elastic"}` + "\n"},
+		{"nested", `{"query":{"match":{"password":{"query":"secret"}}}}`, `{"query":{"match":{"password":"[REDACTED]"}}}` + "\n"},
+		{"in arrays", `{"users":[{"password":"a"},{"password":"b"}]}`, `{"users":[{"password":"[REDACTED]"},{"password":"[REDACTED]"}]}` + "\n"},
+		{"NDJSON", "{\"index\":{\"_id\":\"1\"}}\n{\"password\":\"secret\"}\n", "{\"index\":{\"_id\":\"1\"}}\n{\"password\":\"[REDACTED]\"}\n"},
+		{"numbers kept", `{"count":12345678901234567890}`, `{"count":12345678901234567890}` + "\n"},
+		{"invalid JSON", `{"password":"secret"`, redacted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(redactJSON([]byte(tt.body), []string{"password"}))
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
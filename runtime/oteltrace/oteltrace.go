@@ -0,0 +1,57 @@
+// Package oteltrace traces the operations of slimlastic clients as OpenTelemetry spans
+//
+//	c, err := NewExampleElasticsearchClient(ctx, url, ExampleElasticsearchClientWithInstrumentation(oteltrace.New(otel.GetTracerProvider())))
+package oteltrace
+
+import (
+	"context"
+
+	"github.com/fvosberg/slimlastic/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a runtime.Instrumentation, which starts a span for every operation
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer with a tracer of the provider
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: tp.Tracer("github.com/fvosberg/slimlastic/runtime")}
+}
+
+// OperationStarted implements runtime.Instrumentation
+func (t *Tracer) OperationStarted(ctx context.Context, op *runtime.Operation) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "elasticsearch "+op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(op.Start),
+		trace.WithAttributes(
+			attribute.String("db.system", "elasticsearch"),
+			attribute.String("db.operation", op.Name),
+			attribute.String("db.elasticsearch.index", op.Index),
+		),
+	)
+	return ctx
+}
+
+// OperationFinished implements runtime.Instrumentation
+func (t *Tracer) OperationFinished(ctx context.Context, op *runtime.Operation) {
+	span := trace.SpanFromContext(ctx)
+	if op.Status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", op.Status))
+	}
+	if op.Took > 0 || op.Hits > 0 {
+		span.SetAttributes(
+			attribute.Int64("db.elasticsearch.took_ms", op.Took.Milliseconds()),
+			attribute.Int("db.elasticsearch.hits", op.Hits),
+		)
+	}
+	if op.Err != nil {
+		span.SetAttributes(attribute.String("error.type", op.ErrorType))
+		span.RecordError(op.Err)
+		span.SetStatus(codes.Error, op.ErrorType)
+	}
+	span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+}
@@ -0,0 +1,107 @@
+package oteltrace
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fvosberg/slimlastic/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type doc struct {
+	ID string `json:"-"`
+}
+
+var model = runtime.Model[doc]{
+	Index:           "docs",
+	Type:            "doc",
+	IndexDefinition: `{}`,
+	ID:              func(d *doc) string { return d.ID },
+	SetID: func(d *doc, id string) error {
+		d.ID = id
+		return nil
+	},
+}
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		attrs  map[attribute.Key]attribute.Value // expected attributes of the span
+		code   codes.Code
+	}{
+		{
+			name:   "search",
+			status: http.StatusOK,
+			body:   `{"took":20,"hits":{"total":1,"hits":[{"_id":"a"}]}}`,
+			attrs: map[attribute.Key]attribute.Value{
+				"db.system":                 attribute.StringValue("elasticsearch"),
+				"db.operation":              attribute.StringValue("GetList"),
+				"db.elasticsearch.index":    attribute.StringValue("docs"),
+				"http.response.status_code": attribute.IntValue(http.StatusOK),
+				"db.elasticsearch.took_ms":  attribute.Int64Value(20),
+				"db.elasticsearch.hits":     attribute.IntValue(1),
+			},
+			code: codes.Unset,
+		},
+		{
+			name:   "error",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"type":"parsing_exception","reason":"unknown query"},"status":400}`,
+			attrs: map[attribute.Key]attribute.Value{
+				"db.operation":              attribute.StringValue("GetList"),
+				"http.response.status_code": attribute.IntValue(http.StatusBadRequest),
+				"error.type":                attribute.StringValue("parsing_exception"),
+			},
+			code: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			c := runtime.NewClient(srv.URL, model, 5*time.Second)
+			c.Instrument(New(tp))
+
+			_, _ = c.GetList(context.Background(), 0, 10)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, nested operations are part of it, got %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "elasticsearch GetList" || span.SpanKind != trace.SpanKindClient {
+				t.Errorf("expected the client span elasticsearch GetList, got %s %s", span.SpanKind, span.Name)
+			}
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, a := range span.Attributes {
+				attrs[a.Key] = a.Value
+			}
+			for k, v := range tt.attrs {
+				if attrs[k] != v {
+					t.Errorf("expected attribute %s %s, got %s", k, v.Emit(), attrs[k].Emit())
+				}
+			}
+			if span.Status.Code != tt.code {
+				t.Errorf("expected status %s, got %s", tt.code, span.Status.Code)
+			}
+			if tt.code == codes.Error && len(span.Events) != 1 {
+				t.Errorf("expected the error to be recorded, got %d events", len(span.Events))
+			}
+		})
+	}
+}
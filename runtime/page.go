@@ -0,0 +1,144 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCursor is returned by GetPage for cursors, which weren't returned by
+// GetPage for the same sort or have been modified
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// defaultCursorKey signs the cursors, when no key is set with SetCursorKey
+// It's only valid for the running process
+var defaultCursorKey = func() []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		panic(errors.Wrap(err, "couldn't generate cursor key"))
+	}
+	return key
+}()
+
+// Page is a page of documents returned by GetPage
+type Page[T any] struct {
+	Items      []T
+	NextCursor string // Cursor of the next page, empty for the last page
+}
+
+// SetCursorKey sets the key which signs the cursors of GetPage
+// It has to be shared by all processes, which accept the cursors of each other
+func (c *Client[T]) SetCursorKey(key []byte) {
+	c.cursorKey = key
+}
+
+// GetPage returns size documents matching the query, starting after the document the cursor
+// points to, or at the first document for an empty cursor. The documents are sorted by the sorts
+// of the query and the IDPath of the model as tiebreaker. From and Size of the query are ignored
+// The cursors are signed, a cursor which has been modified or was returned for another sort is
+// rejected with ErrInvalidCursor
+func (c *Client[T]) GetPage(ctx context.Context, q *Query, cursor string, size int) (_ Page[T], err error) {
+	ctx, finish := c.operation(ctx, "GetPage")
+	defer func() { finish(err) }()
+	body := q.body()
+	delete(body, "from")
+	body["size"] = size
+	sort := c.pageSort(q)
+	body["sort"] = sort
+	sortJSON, err := json.Marshal(sort)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	if cursor != "" {
+		after, err := c.decodeCursor(cursor, sortJSON)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		body["search_after"] = after
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	hits, err := c.search(ctx, bytes.NewReader(b), ListOptions{})
+	if err != nil {
+		return Page[T]{}, err
+	}
+	page := Page[T]{Items: make([]T, len(hits))}
+	for n, h := range hits {
+		page.Items[n] = h.Source
+	}
+	if len(hits) > 0 && len(hits) == size {
+		page.NextCursor, err = c.encodeCursor(hits[len(hits)-1].Sort, sortJSON)
+		if err != nil {
+			return Page[T]{}, err
+		}
+	}
+	return page, nil
+}
+
+// pageSort returns the sorts of the query with the IDPath as tiebreaker
+// Models without IDPath fall back to _id, which elasticsearch 7.6+ rejects by default
+func (c *Client[T]) pageSort(q *Query) []map[string]interface{} {
+	idPath := c.model.IDPath
+	if idPath == "" {
+		idPath = "_id"
+	}
+	var sort []map[string]interface{}
+	if q != nil {
+		sort = append(sort, q.sort...)
+	}
+	for _, s := range sort {
+		if _, ok := s[idPath]; ok {
+			return sort
+		}
+	}
+	return append(sort, map[string]interface{}{idPath: map[string]interface{}{"order": Asc}})
+}
+
+// encodeCursor encodes the sort values of a hit as a cursor, signed together with the sort
+func (c *Client[T]) encodeCursor(values []json.RawMessage, sortJSON []byte) (string, error) {
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.signCursor(payload, sortJSON)), nil
+}
+
+// decodeCursor returns the sort values of the cursor, after its signature has been verified
+func (c *Client[T]) decodeCursor(cursor string, sortJSON []byte) ([]json.RawMessage, error) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, c.signCursor(payload, sortJSON)) {
+		return nil, ErrInvalidCursor
+	}
+	var values []json.RawMessage
+	err = json.Unmarshal(payload, &values)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return values, nil
+}
+
+func (c *Client[T]) signCursor(payload, sortJSON []byte) []byte {
+	mac := hmac.New(sha256.New, c.cursorKey)
+	mac.Write(sortJSON)
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
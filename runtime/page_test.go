@@ -0,0 +1,159 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// pageServer is a fake of search_after over its documents sorted by their _id
+type pageServer struct {
+	mu       sync.Mutex
+	docs     []string // IDs of the documents, sorted
+	requests []string // sort and search_after of the requests
+}
+
+func (s *pageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body struct {
+		Size        int             `json:"size"`
+		Sort        json.RawMessage `json:"sort"`
+		SearchAfter []string        `json:"search_after"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	s.requests = append(s.requests, fmt.Sprintf("%s %v", body.Sort, body.SearchAfter))
+	var hits []string
+	for _, id := range s.docs {
+		if len(hits) == body.Size {
+			break
+		}
+		// the last sort value is the _id tiebreaker
+		if len(body.SearchAfter) > 0 && id <= body.SearchAfter[len(body.SearchAfter)-1] {
+			continue
+		}
+		hits = append(hits, fmt.Sprintf(`{"_id":%q,"_source":{},"sort":["name",%q]}`, id, id))
+	}
+	fmt.Fprintf(w, `{"took":1,"hits":{"total":%d,"hits":[%s]}}`, len(s.docs), strings.Join(hits, ","))
+}
+
+func TestGetPage(t *testing.T) {
+	tests := []struct {
+		name  string
+		docs  []string
+		pages []string // IDs of the documents of the pages
+	}{
+		{"last page not full", []string{"a", "b", "c", "d", "e"}, []string{"a,b", "c,d", "e"}},
+		{"last page full", []string{"a", "b", "c", "d"}, []string{"a,b", "c,d", ""}},
+		{"no documents", nil, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &pageServer{docs: tt.docs}
+			c, _ := newTestClient(t, srv)
+			q := &Query{}
+			q.Sort("name", Asc)
+			var (
+				pages  []string
+				cursor string
+			)
+			for {
+				page, err := c.GetPage(context.Background(), q, cursor, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []string
+				for _, d := range page.Items {
+					ids = append(ids, d.ID)
+				}
+				pages = append(pages, strings.Join(ids, ","))
+				cursor = page.NextCursor
+				if cursor == "" || len(pages) > len(tt.pages) {
+					break
+				}
+			}
+
+			if fmt.Sprint(pages) != fmt.Sprint(tt.pages) {
+				t.Errorf("expected pages %q, got %q", tt.pages, pages)
+			}
+			sort := `[{"name":{"order":"asc"}},{"_id":{"order":"asc"}}]`
+			if !strings.HasPrefix(srv.requests[0], sort+" []") {
+				t.Errorf("expected the sort with the _id as tiebreaker, got %s", srv.requests[0])
+			}
+			if len(srv.requests) > 1 && !strings.HasSuffix(srv.requests[1], "[name b]") {
+				t.Errorf("expected search_after the last hit of the first page, got %s", srv.requests[1])
+			}
+		})
+	}
+}
+
+func TestGetPageInvalidCursor(t *testing.T) {
+	srv := &pageServer{docs: []string{"a", "b", "c"}}
+	c, _ := newTestClient(t, srv)
+	q := &Query{}
+	q.Sort("name", Asc)
+	page, err := c.GetPage(context.Background(), q, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := page.NextCursor
+	payload, sig, _ := strings.Cut(cursor, ".")
+
+	otherSort := &Query{}
+	otherSort.Sort("name", Desc)
+	otherKey, _ := newTestClient(t, srv)
+	otherKey.SetCursorKey([]byte("another key"))
+
+	tests := []struct {
+		name   string
+		c      *Client[testDoc]
+		q      *Query
+		cursor string
+	}{
+		{"modified values", c, q, "WyJuYW1lIiwiYyJd." + sig},
+		{"modified signature", c, q, payload + "." + strings.Repeat("A", len(sig))},
+		{"no signature", c, q, payload},
+		{"no base64", c, q, "!!!.???"},
+		{"other sort", c, otherSort, cursor},
+		{"other key", otherKey, q, cursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.requests = nil
+
+			_, err := tt.c.GetPage(context.Background(), tt.q, tt.cursor, 1)
+
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("expected ErrInvalidCursor, got %v", err)
+			}
+			if len(srv.requests) > 0 {
+				t.Errorf("expected no request for an invalid cursor, got %v", srv.requests)
+			}
+		})
+	}
+
+	t.Run("same key", func(t *testing.T) {
+		key := []byte("shared key")
+		c.SetCursorKey(key)
+		otherKey.SetCursorKey(key)
+		page, err := c.GetPage(context.Background(), q, "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		page, err = otherKey.GetPage(context.Background(), q, page.NextCursor, 1)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != "b" {
+			t.Errorf("expected the second document, got %+v", page.Items)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package runtime
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultResurrectTimeout is the time a node is skipped after a connection error, see SetResurrectTimeout
+const DefaultResurrectTimeout = 30 * time.Second
+
+// NodeState is the state of a node of the pool, see Nodes
+type NodeState struct {
+	URL       string    // URL of the node, without credentials
+	Alive     bool      // Whether requests are sent to the node
+	Failures  int       // Number of consecutive connection errors
+	DeadUntil time.Time // When a dead node is tried again
+}
+
+// pool spreads the requests round-robin over the alive nodes
+type pool struct {
+	mu        sync.Mutex
+	nodes     []*node
+	next      int
+	resurrect time.Duration
+	sniff     time.Duration
+	sniffed   time.Time
+	err       error // error of the configured URLs, returned for every request
+}
+
+type node struct {
+	url       *url.URL
+	failures  int
+	deadUntil time.Time
+}
+
+func newPool(urls []string) *pool {
+	p := &pool{resurrect: DefaultResurrectTimeout}
+	p.err = p.setNodes(urls)
+	return p
+}
+
+// setNodes replaces the nodes of the pool, p.mu must be held
+func (p *pool) setNodes(urls []string) error {
+	nodes := make([]*node, 0, len(urls))
+	for _, raw := range urls {
+		raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return errors.Errorf("invalid elasticsearch URL %q", redact(raw))
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return errors.Errorf("elasticsearch URL %s needs a scheme and a host", u.Redacted())
+		}
+		nodes = append(nodes, &node{url: u})
+	}
+	if len(nodes) == 0 {
+		return errors.New("no elasticsearch URL configured")
+	}
+	p.nodes = nodes
+	p.next = 0
+	return nil
+}
+
+// pick returns the next alive node. Dead nodes are resurrected after their timeout, when
+// all nodes are dead, the one which is resurrected next is returned
+func (p *pool) pick() (*node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	now := time.Now()
+	var next *node
+	for i := 0; i < len(p.nodes); i++ {
+		n := p.nodes[(p.next+i)%len(p.nodes)]
+		if !n.deadUntil.After(now) {
+			p.next = (p.next + i + 1) % len(p.nodes)
+			return n, nil
+		}
+		if next == nil || n.deadUntil.Before(next.deadUntil) {
+			next = n
+		}
+	}
+	return next, nil
+}
+
+// markDead skips the node for the resurrect timeout, doubled for every consecutive failure
+func (p *pool) markDead(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.failures++
+	d := p.resurrect << (n.failures - 1)
+	if d > 32*p.resurrect || d <= 0 {
+		d = 32 * p.resurrect
+	}
+	n.deadUntil = time.Now().Add(d)
+}
+
+func (p *pool) markAlive(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.failures = 0
+	n.deadUntil = time.Time{}
+}
+
+func (p *pool) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.nodes)
+}
+
+// sniffDue returns whether the nodes should be sniffed and marks them as sniffed
+func (p *pool) sniffDue() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sniff <= 0 || p.err != nil || time.Since(p.sniffed) < p.sniff {
+		return false
+	}
+	p.sniffed = time.Now()
+	return true
+}
+
+// resolve returns the URL of the path at the node, keeping the escaping of the path, like of a / in an _id
+func (n *node) resolve(path *url.URL) *url.URL {
+	u := *n.url
+	u.Path = strings.TrimRight(n.url.Path, "/") + path.Path
+	u.RawPath = strings.TrimRight(n.url.EscapedPath(), "/") + path.EscapedPath()
+	u.RawQuery = path.RawQuery
+	return &u
+}
+
+// SetNodes sets the URLs of the elasticsearch nodes, the requests are spread round-robin over them
+// When the URLs are invalid, the error is returned for all requests as well
+func (c *Client[T]) SetNodes(urls ...string) error {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.err = c.pool.setNodes(urls)
+	return c.pool.err
+}
+
+// SetResurrectTimeout sets the time a node is skipped after a connection error
+// It's doubled for every consecutive connection error of the node
+func (c *Client[T]) SetResurrectTimeout(d time.Duration) {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.resurrect = d
+}
+
+// SetSniffInterval enables the discovery of the nodes of the cluster with the _nodes/http API
+// Before a request, the nodes are sniffed, when the last sniff is older than d
+func (c *Client[T]) SetSniffInterval(d time.Duration) {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.sniff = d
+}
+
+// Nodes returns the state of the nodes, for diagnostics
+func (c *Client[T]) Nodes() []NodeState {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	now := time.Now()
+	states := make([]NodeState, len(c.pool.nodes))
+	for i, n := range c.pool.nodes {
+		states[i] = NodeState{
+			URL:       n.url.Redacted(),
+			Alive:     !n.deadUntil.After(now),
+			Failures:  n.failures,
+			DeadUntil: n.deadUntil,
+		}
+	}
+	return states
+}
+
+// Sniff replaces the nodes by the nodes of the cluster with an HTTP address, found with the _nodes/http API
+// The scheme and credentials of the current nodes are kept
+func (c *Client[T]) Sniff(ctx context.Context) (err error) {
+	ctx, finish := c.operation(ctx, "Sniff")
+	defer func() { finish(err) }()
+	var response struct {
+		Nodes map[string]struct {
+			HTTP struct {
+				PublishAddress string `json:"publish_address"`
+			} `json:"http"`
+		} `json:"nodes"`
+	}
+	req, err := c.newRequest(ctx, "GET", "/_nodes/http", nil)
+	if err != nil {
+		return err
+	}
+	err = c.do(req, &response)
+	if err != nil {
+		return errors.Wrap(err, "couldn't sniff nodes")
+	}
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	if len(c.pool.nodes) == 0 {
+		return c.pool.err
+	}
+	tmpl := *c.pool.nodes[0].url
+	var urls []string
+	for _, n := range response.Nodes {
+		addr := n.HTTP.PublishAddress
+		if addr == "" {
+			continue
+		}
+		// since elasticsearch 7 the address is formatted as hostname/ip:port
+		if i := strings.Index(addr, "/"); i >= 0 {
+			addr = addr[i+1:]
+		}
+		u := tmpl
+		u.Host = addr
+		urls = append(urls, u.String())
+	}
+	if len(urls) == 0 {
+		return errors.New("couldn't sniff nodes: no node with an HTTP address found")
+	}
+	return c.pool.setNodes(urls)
+}
+
+// isConnectionError returns whether the request failed, because the node couldn't be reached
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isDialError returns whether the connection to the node couldn't be established, so the request wasn't sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// redact removes the credentials of an URL, which couldn't be parsed
+func redact(raw string) string {
+	if i := strings.Index(raw, "@"); i >= 0 {
+		if j := strings.Index(raw, "://"); j >= 0 && j < i {
+			return raw[:j+3] + "xxxxx" + raw[i:]
+		}
+		return "xxxxx" + raw[i:]
+	}
+	return raw
+}
+
+// nodeRequest returns a copy of the request, which is sent to the node
+func nodeRequest(req *http.Request, n *node, first bool) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	r.URL = n.resolve(req.URL)
+	r.Host = ""
+	if !first && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
@@ -0,0 +1,224 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingServer returns a server, which counts the requests to it
+func countingServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// deadURL returns the URL of a server, which has been closed
+func deadURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func TestPoolFailover(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string // alive or dead
+		requests int
+		alive    []int32 // expected requests per node
+		err      bool
+	}{
+		{"round robin", []string{"alive", "alive"}, 4, []int32{2, 2}, false},
+		{"first node dead", []string{"dead", "alive"}, 3, []int32{0, 3}, false},
+		{"second node dead", []string{"alive", "dead", "alive"}, 4, []int32{2, 0, 2}, false},
+		{"all nodes dead", []string{"dead", "dead"}, 1, []int32{0, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]int32, len(tt.nodes))
+			urls := make([]string, len(tt.nodes))
+			for i, n := range tt.nodes {
+				urls[i] = deadURL()
+				if n == "alive" {
+					urls[i] = countingServer(t, &counts[i]).URL
+				}
+			}
+			c := NewClient(strings.Join(urls, ","), testModel, 5*time.Second)
+			var err error
+			for n := 0; n < tt.requests; n++ {
+				_, err = c.IndexExists(context.Background())
+			}
+			if tt.err != (err != nil) {
+				t.Fatalf("expected an error %t, got %v", tt.err, err)
+			}
+			for i := range counts {
+				if counts[i] != tt.alive[i] {
+					t.Errorf("expected %d requests to node %d, got %d", tt.alive[i], i, counts[i])
+				}
+			}
+			for i, s := range c.Nodes() {
+				if s.Alive != (tt.nodes[i] == "alive") {
+					t.Errorf("expected node %d to be %s, got %+v", i, tt.nodes[i], s)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolResurrect(t *testing.T) {
+	c := NewClient(deadURL(), testModel, 5*time.Second)
+	c.SetResurrectTimeout(10 * time.Millisecond)
+	for failures := 1; failures <= 3; failures++ {
+		_, err := c.IndexExists(context.Background())
+		if err == nil {
+			t.Fatal("expected an error of the dead node")
+		}
+		s := c.Nodes()[0]
+		if s.Alive || s.Failures != failures {
+			t.Fatalf("expected a dead node with %d failures, got %+v", failures, s)
+		}
+		// the node is tried again, even though it's dead, because it's the only one
+	}
+	s := c.Nodes()[0]
+	if wait := time.Until(s.DeadUntil); wait < 20*time.Millisecond || wait > 40*time.Millisecond {
+		t.Errorf("expected the resurrect timeout to be doubled for every failure, got %s", wait)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	var requests int32
+	target := countingServer(t, &requests)
+	addr := strings.TrimPrefix(target.URL, "http://")
+	tests := []struct {
+		name    string
+		address string // publish_address of the sniffed node
+		err     bool
+	}{
+		{"address", addr, false},
+		{"address with hostname", "localhost/" + addr, false},
+		{"no address", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sniffs int32
+			c, seed := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/_nodes/http" {
+					t.Errorf("unexpected request %s to the seed node", r.URL.Path)
+					return
+				}
+				atomic.AddInt32(&sniffs, 1)
+				fmt.Fprintf(w, `{"nodes":{"a":{"http":{"publish_address":%q}}}}`, tt.address)
+			}))
+			err := c.Sniff(context.Background())
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if nodes := c.Nodes(); len(nodes) != 1 || nodes[0].URL != seed.URL {
+					t.Errorf("expected the seed node to be kept, got %+v", nodes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if nodes := c.Nodes(); len(nodes) != 1 || nodes[0].URL != target.URL {
+				t.Fatalf("expected node %s, got %+v", target.URL, nodes)
+			}
+		})
+	}
+}
+
+func TestSniffInterval(t *testing.T) {
+	var requests, sniffs int32
+	target := countingServer(t, &requests)
+	c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sniffs, 1)
+		fmt.Fprintf(w, `{"nodes":{"a":{"http":{"publish_address":%q}}}}`, strings.TrimPrefix(target.URL, "http://"))
+	}))
+	c.SetSniffInterval(time.Hour)
+	for n := 0; n < 3; n++ {
+		_, err := c.IndexExists(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sniffs != 1 || requests != 3 {
+		t.Errorf("expected 1 sniff and 3 requests to the sniffed node, got %d and %d", sniffs, requests)
+	}
+}
+
+func TestSetNodes(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []string
+		want []string // expected URLs of the nodes, nil for an error
+	}{
+		{"trailing slashes", []string{"http://a:9200/", " http://b:9200 "}, []string{"http://a:9200", "http://b:9200"}},
+		{"credentials are redacted", []string{"https://elastic:secret@a:9200"}, []string{"https://elastic:xxxxx@a:9200"}},
+		{"missing scheme", []string{"a:9200"}, nil},
+		{"no URL", []string{""}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient("http://localhost:9200", testModel, time.Second)
+			err := c.SetNodes(tt.urls...)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if _, err := c.IndexExists(context.Background()); err == nil {
+					t.Error("expected the error to be returned by requests")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var urls []string
+			for _, n := range c.Nodes() {
+				urls = append(urls, n.URL)
+			}
+			if strings.Join(urls, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected nodes %v, got %v", tt.want, urls)
+			}
+		})
+	}
+}
+
+func TestNodeResolve(t *testing.T) {
+	tests := []struct {
+		node string
+		path string
+		want string
+	}{
+		{"http://a:9200", "/docs/_search?size=1", "http://a:9200/docs/_search?size=1"},
+		{"http://a:9200/es/", "/docs/_search", "http://a:9200/es/docs/_search"},
+		{"http://a:9200", "/docs/doc/a%2Fb", "http://a:9200/docs/doc/a%2Fb"},
+		{"http://a:9200/es%2Fv8", "/docs/doc/a%3Fb", "http://a:9200/es%2Fv8/docs/doc/a%3Fb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p := newPool([]string{tt.node})
+			if p.err != nil {
+				t.Fatal(p.err)
+			}
+			path, err := url.Parse(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.nodes[0].resolve(path).String(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
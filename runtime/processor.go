@@ -0,0 +1,327 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBulkProcessorClosed is returned when an operation is added to a closed BulkProcessor
+var ErrBulkProcessorClosed = errors.New("bulk processor is closed")
+
+// BulkProcessorConfig configures a BulkProcessor
+type BulkProcessorConfig[T any] struct {
+	Workers       int                      // Number of concurrent bulk requests
+	FlushInterval time.Duration            // Buffered operations are flushed at least this often, 0 disables it
+	FlushDocs     int                      // Number of buffered operations, which triggers a flush
+	FlushBytes    int                      // Size of the buffered operations, which triggers a flush
+	Retries       int                      // How often failed operations are retried
+	Backoff       time.Duration            // Wait before the first retry, doubled for every further retry
+	MaxBackoff    time.Duration            // Maximum wait before a retry, 0 for none
+	OnFailure     func([]BulkOp[T], error) // Called with the operations, which failed finally
+}
+
+// BulkProcessorOption is an option for NewBulkProcessor
+type BulkProcessorOption[T any] func(*BulkProcessorConfig[T])
+
+// WithBulkWorkers sets the number of concurrent bulk requests
+func WithBulkWorkers[T any](n int) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.Workers = n
+	}
+}
+
+// WithFlushInterval flushes the buffered operations at least every d
+func WithFlushInterval[T any](d time.Duration) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.FlushInterval = d
+	}
+}
+
+// WithFlushDocs flushes the buffered operations, when there are n of them
+func WithFlushDocs[T any](n int) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.FlushDocs = n
+	}
+}
+
+// WithFlushBytes flushes the buffered operations, when their request body reaches n bytes
+func WithFlushBytes[T any](n int) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.FlushBytes = n
+	}
+}
+
+// WithBulkRetries retries failed operations n times, waiting backoff before the
+// first retry and doubling it for every further retry, up to maxBackoff unless it's 0
+// Documents without ID are only retried, when elasticsearch didn't process the request
+func WithBulkRetries[T any](n int, backoff, maxBackoff time.Duration) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.Retries = n
+		cfg.Backoff = backoff
+		cfg.MaxBackoff = maxBackoff
+	}
+}
+
+// OnBulkFailure reports the operations, which failed finally, to f. The error is a
+// *BulkError for operations rejected by elasticsearch, otherwise the error of the request
+// f is called by the workers concurrently
+func OnBulkFailure[T any](f func([]BulkOp[T], error)) BulkProcessorOption[T] {
+	return func(cfg *BulkProcessorConfig[T]) {
+		cfg.OnFailure = f
+	}
+}
+
+// BulkProcessor buffers operations added from many goroutines and sends them
+// to the _bulk API in the background, see NewBulkProcessor
+// Documents must not be modified after they are added, their _id is set when they are indexed
+type BulkProcessor[T any] struct {
+	c     *Client[T]
+	cfg   BulkProcessorConfig[T]
+	queue chan *bulkBatch[T]
+	stop  chan struct{}
+	wg    sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	batch  *bulkBatch[T]
+	closed bool
+}
+
+type bulkBatch[T any] struct {
+	ops  []BulkOp[T]
+	body bytes.Buffer
+}
+
+// NewBulkProcessor starts a BulkProcessor, which has to be stopped by Close
+func (c *Client[T]) NewBulkProcessor(opts ...BulkProcessorOption[T]) *BulkProcessor[T] {
+	cfg := BulkProcessorConfig[T]{
+		Workers:       1,
+		FlushInterval: time.Second,
+		FlushDocs:     DefaultBulkMaxDocs,
+		FlushBytes:    DefaultBulkMaxBytes,
+		Retries:       3,
+		Backoff:       100 * time.Millisecond,
+		MaxBackoff:    5 * time.Second,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	p := &BulkProcessor[T]{
+		c:     c,
+		cfg:   cfg,
+		queue: make(chan *bulkBatch[T]),
+		stop:  make(chan struct{}),
+		batch: &bulkBatch[T]{},
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.wg.Add(cfg.Workers)
+	for n := 0; n < cfg.Workers; n++ {
+		go p.work()
+	}
+	if cfg.FlushInterval > 0 {
+		go p.tick()
+	}
+	return p
+}
+
+// Index adds the creation or update of the document
+func (p *BulkProcessor[T]) Index(doc *T) error {
+	return p.Add(IndexOp(doc))
+}
+
+// Delete adds the deletion of the document with the given _id
+func (p *BulkProcessor[T]) Delete(id string) error {
+	return p.Add(DeleteOp[T](id))
+}
+
+// Add adds the operation to the buffer. It blocks, when the buffer is full and all workers are busy
+func (p *BulkProcessor[T]) Add(op BulkOp[T]) error {
+	line, err := p.c.encodeBulkOp(op)
+	if err != nil {
+		return errors.Wrap(err, "couldn't encode bulk operation")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrBulkProcessorClosed
+	}
+	if len(p.batch.ops) > 0 && p.batch.body.Len()+len(line) > p.cfg.FlushBytes {
+		p.flush()
+	}
+	p.batch.ops = append(p.batch.ops, op)
+	p.batch.body.Write(line)
+	if len(p.batch.ops) >= p.cfg.FlushDocs {
+		p.flush()
+	}
+	return nil
+}
+
+// Flush hands the buffered operations to the workers
+func (p *BulkProcessor[T]) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.flush()
+	}
+}
+
+// flush hands the buffered operations to the workers, p.mu must be held
+func (p *BulkProcessor[T]) flush() {
+	if len(p.batch.ops) == 0 {
+		return
+	}
+	p.queue <- p.batch
+	p.batch = &bulkBatch[T]{}
+}
+
+// Close flushes the buffered operations and waits until all of them are sent
+// When ctx is done before, the pending requests are canceled and ctx.Err() is returned
+func (p *BulkProcessor[T]) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.stop)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.flush()
+		close(p.queue)
+		p.mu.Unlock()
+		p.wg.Wait()
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *BulkProcessor[T]) tick() {
+	t := time.NewTicker(p.cfg.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			p.Flush()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *BulkProcessor[T]) work() {
+	defer p.wg.Done()
+	for b := range p.queue {
+		p.send(b.ops, &b.body)
+	}
+}
+
+// send sends the operations, retrying those which failed temporarily
+func (p *BulkProcessor[T]) send(ops []BulkOp[T], body *bytes.Buffer) {
+	for attempt := 0; ; attempt++ {
+		result := &BulkResult{}
+		err := p.c.sendBulk(p.ctx, ops, body, BulkConfig{Refresh: "false"}, result)
+		var (
+			retry, failed           []BulkOp[T]
+			retryItems, failedItems []BulkItem
+		)
+		if err != nil && !temporary(err) {
+			// like a bad request or missing permissions, which would fail again
+			p.fail(ops, err)
+			return
+		}
+		if err != nil {
+			var unsafe []BulkOp[T]
+			for _, op := range ops {
+				if unprocessed(err) || p.c.bulkIdempotent([]BulkOp[T]{op}) {
+					retry = append(retry, op)
+				} else {
+					unsafe = append(unsafe, op)
+				}
+			}
+			if len(unsafe) > 0 {
+				// the request might have been processed, retrying would index the documents twice with new IDs
+				p.fail(unsafe, err)
+			}
+		}
+		for n, item := range result.Items {
+			switch {
+			case !item.Failed():
+			case item.Status == 429 || item.Status >= 500:
+				retry = append(retry, ops[n])
+				retryItems = append(retryItems, item)
+			default:
+				failed = append(failed, ops[n])
+				failedItems = append(failedItems, item)
+			}
+		}
+		if len(failed) > 0 {
+			p.fail(failed, &BulkError{Items: failedItems})
+		}
+		if len(retry) == 0 {
+			return
+		}
+		if err == nil {
+			err = &BulkError{Items: retryItems}
+		}
+		if attempt >= p.cfg.Retries || !p.wait(attempt) {
+			p.fail(retry, err)
+			return
+		}
+		ops = ops[:0:0]
+		body = &bytes.Buffer{}
+		for _, op := range retry {
+			line, err := p.c.encodeBulkOp(op)
+			if err != nil {
+				p.fail([]BulkOp[T]{op}, err)
+				continue
+			}
+			ops = append(ops, op)
+			body.Write(line)
+		}
+		if len(ops) == 0 {
+			return
+		}
+	}
+}
+
+// unprocessed returns whether the failed request can be sent again, because elasticsearch didn't process it
+func unprocessed(err error) bool {
+	var e *Error
+	return isDialError(err) || errors.As(err, &e) && e.Status == http.StatusTooManyRequests
+}
+
+// wait waits before the retry after the given attempt, it returns false when the processor is canceled
+func (p *BulkProcessor[T]) wait(attempt int) bool {
+	t := time.NewTimer(backoff(attempt, p.cfg.Backoff, p.cfg.MaxBackoff))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+func (p *BulkProcessor[T]) fail(ops []BulkOp[T], err error) {
+	if p.cfg.OnFailure != nil {
+		p.cfg.OnFailure(ops, err)
+	}
+}
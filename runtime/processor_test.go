@@ -0,0 +1,251 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBulkProcessorRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []string // IDs of the indexed documents, 1 and 2 by default
+		responses []string // status of the request, or the statuses of the items separated by commas like item:201,429
+		requests  int32    // expected number of requests
+		failed    int      // expected number of operations reported as failed
+		failure   error    // expected kind of the reported error
+	}{
+		{
+			name:      "success",
+			responses: []string{"item:201,201"},
+			requests:  1,
+		},
+		{
+			name:      "rejected items are retried",
+			responses: []string{"item:201,429", "item:201"},
+			requests:  2,
+		},
+		{
+			name:      "failed items aren't retried",
+			responses: []string{"item:201,400"},
+			requests:  1,
+			failed:    1,
+			failure:   &BulkError{},
+		},
+		{
+			name:      "unavailable cluster is retried",
+			responses: []string{"503", "503", "item:201,201"},
+			requests:  3,
+		},
+		{
+			name:      "retries are exhausted",
+			responses: []string{"503", "503", "503", "503"},
+			requests:  4,
+			failed:    2,
+			failure:   ErrUnavailable,
+		},
+		{
+			name:      "documents without ID aren't retried after a failed request",
+			ids:       []string{"1", ""},
+			responses: []string{"503", "item:201"},
+			requests:  2,
+			failed:    1,
+			failure:   ErrUnavailable,
+		},
+		{
+			name:      "documents without ID are retried after a rejected request",
+			ids:       []string{"", ""},
+			responses: []string{"429", "item:201,201"},
+			requests:  2,
+		},
+		{
+			name:      "bad request isn't retried",
+			responses: []string{"400"},
+			requests:  1,
+			failed:    2,
+			failure:   &Error{},
+		},
+		{
+			name:      "missing permissions aren't retried",
+			responses: []string{"403"},
+			requests:  1,
+			failed:    2,
+			failure:   &Error{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				res := tt.responses[len(tt.responses)-1]
+				if int(n) <= len(tt.responses) {
+					res = tt.responses[n-1]
+				}
+				if !strings.HasPrefix(res, "item:") {
+					var status int
+					fmt.Sscan(res, &status)
+					w.WriteHeader(status)
+					fmt.Fprintf(w, `{"error":{"type":"some_exception","reason":"failed"},"status":%d}`, status)
+					return
+				}
+				writeBulkResponse(t, w, r, strings.Split(strings.TrimPrefix(res, "item:"), ","))
+			}))
+			var (
+				mu     sync.Mutex
+				failed int
+				errs   []error
+			)
+			p := c.NewBulkProcessor(
+				WithBulkRetries[testDoc](3, time.Millisecond, 0),
+				OnBulkFailure(func(ops []BulkOp[testDoc], err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					failed += len(ops)
+					errs = append(errs, err)
+				}),
+			)
+			ids := tt.ids
+			if ids == nil {
+				ids = []string{"1", "2"}
+			}
+			for _, id := range ids {
+				err := p.Index(&testDoc{ID: id})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := p.Close(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, requests)
+			}
+			if failed != tt.failed {
+				t.Errorf("expected %d failed operations, got %d: %v", tt.failed, failed, errs)
+			}
+			for _, err := range errs {
+				switch target := tt.failure.(type) {
+				case *BulkError:
+					if !errors.As(err, &target) {
+						t.Errorf("expected a bulk error, got %v", err)
+					}
+				case *Error:
+					if !errors.As(err, &target) || target.Status < 400 {
+						t.Errorf("expected an elasticsearch error, got %v", err)
+					}
+				default:
+					if !errors.Is(err, tt.failure) {
+						t.Errorf("expected %v, got %v", tt.failure, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBulkProcessorFlushes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []BulkProcessorOption[testDoc]
+		docs     int
+		requests int32
+	}{
+		{"by number of documents", []BulkProcessorOption[testDoc]{WithFlushDocs[testDoc](2), WithFlushInterval[testDoc](0)}, 5, 3},
+		{"by size", []BulkProcessorOption[testDoc]{WithFlushBytes[testDoc](50), WithFlushInterval[testDoc](0)}, 4, 4},
+		{"on close", []BulkProcessorOption[testDoc]{WithFlushInterval[testDoc](0)}, 5, 1},
+		{"concurrently", []BulkProcessorOption[testDoc]{WithFlushDocs[testDoc](1), WithBulkWorkers[testDoc](4)}, 20, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests, docs int32
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				statuses := writeBulkResponse(t, w, r, nil)
+				atomic.AddInt32(&docs, int32(statuses))
+			}))
+			p := c.NewBulkProcessor(tt.opts...)
+			var wg sync.WaitGroup
+			for n := 0; n < tt.docs; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+					err := p.Index(&testDoc{ID: fmt.Sprint(n), Name: "doc"})
+					if err != nil {
+						t.Error(err)
+					}
+				}(n)
+			}
+			wg.Wait()
+			err := p.Close(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, requests)
+			}
+			if docs != int32(tt.docs) {
+				t.Errorf("expected %d documents, got %d", tt.docs, docs)
+			}
+			if !errors.Is(p.Index(&testDoc{}), ErrBulkProcessorClosed) {
+				t.Errorf("expected ErrBulkProcessorClosed after Close")
+			}
+		})
+	}
+}
+
+// writeBulkResponse responds to the bulk request with the statuses for its items, 201 by default
+// It returns the number of items
+func writeBulkResponse(t *testing.T, w http.ResponseWriter, r *http.Request, statuses []string) int {
+	t.Helper()
+	var items []map[string]interface{}
+	s := bufio.NewScanner(r.Body)
+	for s.Scan() {
+		var action map[string]struct {
+			ID string `json:"_id"`
+		}
+		if json.Unmarshal(s.Bytes(), &action) != nil {
+			continue
+		}
+		index, isIndex := action["index"]
+		del, isDelete := action["delete"]
+		if !isIndex && !isDelete {
+			continue
+		}
+		status := 201
+		if len(items) < len(statuses) {
+			fmt.Sscan(statuses[len(items)], &status)
+		}
+		id := index.ID + del.ID
+		if id == "" {
+			id = fmt.Sprintf("generated%d", len(items))
+		}
+		item := map[string]interface{}{"_id": id, "status": status}
+		if status >= 300 {
+			item["error"] = map[string]string{"type": "some_exception", "reason": "failed"}
+		}
+		if isIndex {
+			items = append(items, map[string]interface{}{"index": item})
+		} else {
+			items = append(items, map[string]interface{}{"delete": item})
+		}
+		if isIndex {
+			s.Scan() // document
+		}
+	}
+	err := json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": false, "items": items})
+	if err != nil {
+		t.Error(err)
+	}
+	return len(items)
+}
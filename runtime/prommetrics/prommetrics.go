@@ -0,0 +1,91 @@
+// Package prommetrics records the operations of slimlastic clients as prometheus metrics
+//
+//	m := prommetrics.New("myapp")
+//	prometheus.MustRegister(m)
+//	c, err := NewExampleElasticsearchClient(ctx, url, ExampleElasticsearchClientWithInstrumentation(m))
+package prommetrics
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/fvosberg/slimlastic/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a runtime.Instrumentation and a prometheus.Collector of the metrics
+// of the operations, labeled by operation and index
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	took     *prometheus.HistogramVec
+	hits     *prometheus.HistogramVec
+}
+
+// New creates the metrics with the namespace, which may be empty
+func New(namespace string) *Metrics {
+	labels := []string{"operation", "index"}
+	return &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "elasticsearch",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the operations of the elasticsearch clients, labeled with the HTTP status of the last response",
+			Buckets:   prometheus.DefBuckets,
+		}, append(labels, "status")),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "elasticsearch",
+			Name:      "operation_errors_total",
+			Help:      "Number of failed operations of the elasticsearch clients, labeled with the elasticsearch error type",
+		}, append(labels, "error_type")),
+		took: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "elasticsearch",
+			Name:      "took_seconds",
+			Help:      "Time elasticsearch took for the searches and bulk requests",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		hits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "elasticsearch",
+			Name:      "hits",
+			Help:      "Number of documents returned by the searches or sent with the bulk requests",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}, labels),
+	}
+}
+
+// OperationStarted implements runtime.Instrumentation
+func (m *Metrics) OperationStarted(ctx context.Context, op *runtime.Operation) context.Context {
+	return ctx
+}
+
+// OperationFinished implements runtime.Instrumentation
+func (m *Metrics) OperationFinished(ctx context.Context, op *runtime.Operation) {
+	m.duration.WithLabelValues(op.Name, op.Index, strconv.Itoa(op.Status)).Observe(op.Duration.Seconds())
+	if op.Err != nil {
+		m.errors.WithLabelValues(op.Name, op.Index, op.ErrorType).Inc()
+		return
+	}
+	if op.Took > 0 || op.Hits > 0 {
+		m.took.WithLabelValues(op.Name, op.Index).Observe(op.Took.Seconds())
+		m.hits.WithLabelValues(op.Name, op.Index).Observe(float64(op.Hits))
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+	m.took.Describe(ch)
+	m.hits.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+	m.took.Collect(ch)
+	m.hits.Collect(ch)
+}
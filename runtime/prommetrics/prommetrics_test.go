@@ -0,0 +1,97 @@
+package prommetrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvosberg/slimlastic/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type doc struct {
+	ID string `json:"-"`
+}
+
+var model = runtime.Model[doc]{
+	Index:           "docs",
+	Type:            "doc",
+	IndexDefinition: `{}`,
+	ID:              func(d *doc) string { return d.ID },
+	SetID: func(d *doc, id string) error {
+		d.ID = id
+		return nil
+	},
+}
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		metrics string // expected metrics, without the duration histogram
+		count   int    // expected number of observed durations
+	}{
+		{
+			name:   "search",
+			status: http.StatusOK,
+			body:   `{"took":20,"hits":{"total":3,"hits":[{"_id":"a"},{"_id":"b"},{"_id":"c"}]}}`,
+			metrics: `
+# HELP test_elasticsearch_hits Number of documents returned by the searches or sent with the bulk requests
+# TYPE test_elasticsearch_hits histogram
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="1"} 0
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="4"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="16"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="64"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="256"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="1024"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="4096"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="16384"} 1
+test_elasticsearch_hits_bucket{index="docs",operation="GetList",le="+Inf"} 1
+test_elasticsearch_hits_sum{index="docs",operation="GetList"} 3
+test_elasticsearch_hits_count{index="docs",operation="GetList"} 1
+`,
+			count: 1,
+		},
+		{
+			name:   "error",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"type":"parsing_exception","reason":"unknown query"},"status":400}`,
+			metrics: `
+# HELP test_elasticsearch_operation_errors_total Number of failed operations of the elasticsearch clients, labeled with the elasticsearch error type
+# TYPE test_elasticsearch_operation_errors_total counter
+test_elasticsearch_operation_errors_total{error_type="parsing_exception",index="docs",operation="GetList"} 1
+`,
+			count: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			m := New("test")
+			reg := prometheus.NewPedanticRegistry()
+			reg.MustRegister(m)
+			c := runtime.NewClient(srv.URL, model, 5*time.Second)
+			c.Instrument(m)
+
+			_, _ = c.GetList(context.Background(), 0, 10)
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(tt.metrics), "test_elasticsearch_hits", "test_elasticsearch_operation_errors_total")
+			if err != nil {
+				t.Error(err)
+			}
+			if n := testutil.CollectAndCount(m, "test_elasticsearch_operation_duration_seconds"); n != tt.count {
+				t.Errorf("expected %d durations, got %d", tt.count, n)
+			}
+		})
+	}
+}
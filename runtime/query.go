@@ -0,0 +1,139 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Clause is a clause of the elasticsearch query DSL, like {"term": {"foo": "bar"}}
+type Clause map[string]interface{}
+
+// Term matches documents, which contain exactly the value in the field
+func Term(field string, v interface{}) Clause {
+	return Clause{"term": map[string]interface{}{field: v}}
+}
+
+// Terms matches documents, which contain exactly one of the values in the field
+func Terms[V any](field string, v ...V) Clause {
+	return Clause{"terms": map[string]interface{}{field: v}}
+}
+
+// Match matches documents, which match the analyzed text in the field
+func Match(field, txt string) Clause {
+	return Clause{"match": map[string]interface{}{field: txt}}
+}
+
+// RangeOp is an operator of a range clause
+type RangeOp string
+
+// operators of range clauses
+const (
+	Gt  RangeOp = "gt"
+	Gte RangeOp = "gte"
+	Lt  RangeOp = "lt"
+	Lte RangeOp = "lte"
+)
+
+// Range matches documents, whose field is in the range described by the operator and value
+func Range(field string, op RangeOp, v interface{}) Clause {
+	return Clause{"range": map[string]interface{}{field: map[string]interface{}{string(op): v}}}
+}
+
+// Between matches documents, whose field is between from and to, both inclusive
+func Between(field string, from, to interface{}) Clause {
+	return Clause{"range": map[string]interface{}{field: map[string]interface{}{"gte": from, "lte": to}}}
+}
+
+// Nested matches documents, which contain a nested document at path matching the clause
+func Nested(path string, c Clause) Clause {
+	return Clause{"nested": map[string]interface{}{"path": path, "query": c}}
+}
+
+// SortOrder is the order of a sort
+type SortOrder string
+
+// orders of a sort
+const (
+	Asc  SortOrder = "asc"
+	Desc SortOrder = "desc"
+)
+
+// Query is a search request, built from clauses. Match clauses contribute to
+// the score of the documents, all other clauses are filters
+type Query struct {
+	must   []Clause
+	filter []Clause
+	sort   []map[string]interface{}
+	from   *int
+	size   *int
+}
+
+// Must adds a clause, which contributes to the score of the documents
+func (q *Query) Must(c Clause) {
+	q.must = append(q.must, c)
+}
+
+// Filter adds a clause, which doesn't contribute to the score of the documents
+func (q *Query) Filter(c Clause) {
+	q.filter = append(q.filter, c)
+}
+
+// Sort adds a sort by the field, sorts are applied in the order they are added
+func (q *Query) Sort(field string, o SortOrder) {
+	q.sort = append(q.sort, map[string]interface{}{field: map[string]interface{}{"order": o}})
+}
+
+// From sets the offset of the first hit
+func (q *Query) From(n int) {
+	q.from = &n
+}
+
+// Size sets the maximum number of hits
+func (q *Query) Size(n int) {
+	q.size = &n
+}
+
+// MarshalJSON encodes the query as the body of a search request
+func (q *Query) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.body())
+}
+
+// body returns the body of a search request, a nil query matches all documents
+func (q *Query) body() map[string]interface{} {
+	body := map[string]interface{}{}
+	if q == nil {
+		return body
+	}
+	if len(q.must) > 0 || len(q.filter) > 0 {
+		b := map[string]interface{}{}
+		if len(q.must) > 0 {
+			b["must"] = q.must
+		}
+		if len(q.filter) > 0 {
+			b["filter"] = q.filter
+		}
+		body["query"] = map[string]interface{}{"bool": b}
+	}
+	if len(q.sort) > 0 {
+		body["sort"] = q.sort
+	}
+	if q.from != nil {
+		body["from"] = *q.from
+	}
+	if q.size != nil {
+		body["size"] = *q.size
+	}
+	return body
+}
+
+// Find returns the documents matching the query
+func (c *Client[T]) Find(ctx context.Context, q *Query, opts ...ListOption) (_ []T, err error) {
+	ctx, finish := c.operation(ctx, "Find")
+	defer func() { finish(err) }()
+	b, err := json.Marshal(q.body())
+	if err != nil {
+		return nil, err
+	}
+	return c.DoListRequest(ctx, bytes.NewReader(b), opts...)
+}
@@ -0,0 +1,112 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestClauses(t *testing.T) {
+	tests := []struct {
+		name   string
+		clause Clause
+		want   string
+	}{
+		{"term", Term("tag", "go"), `{"term":{"tag":"go"}}`},
+		{"terms", Terms("count", 1, 2), `{"terms":{"count":[1,2]}}`},
+		{"terms without values", Terms[string]("tag"), `{"terms":{"tag":null}}`},
+		{"match", Match("title", "hello world"), `{"match":{"title":"hello world"}}`},
+		{"range", Range("count", Gt, 3), `{"range":{"count":{"gt":3}}}`},
+		{"between", Between("count", 1, 5), `{"range":{"count":{"gte":1,"lte":5}}}`},
+		{"nested", Nested("comments", Term("comments.author", "jo")), `{"nested":{"path":"comments","query":{"term":{"comments.author":"jo"}}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.clause)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, b)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(q *Query)
+		want  string
+	}{
+		{"empty", func(q *Query) {}, `{}`},
+		{"must", func(q *Query) { q.Must(Match("title", "go")) }, `{"query":{"bool":{"must":[{"match":{"title":"go"}}]}}}`},
+		{"filter", func(q *Query) { q.Filter(Term("tag", "go")) }, `{"query":{"bool":{"filter":[{"term":{"tag":"go"}}]}}}`},
+		{
+			name: "all",
+			build: func(q *Query) {
+				q.Must(Match("title", "go"))
+				q.Filter(Term("tag", "go"))
+				q.Filter(Range("count", Lte, 3))
+				q.Sort("count", Desc)
+				q.Sort("title", Asc)
+				q.From(10)
+				q.Size(5)
+			},
+			want: `{"from":10,"query":{"bool":{"filter":[{"term":{"tag":"go"}},{"range":{"count":{"lte":3}}}],"must":[{"match":{"title":"go"}}]}},"size":5,"sort":[{"count":{"order":"desc"}},{"title":{"order":"asc"}}]}`,
+		},
+		{"zero size", func(q *Query) { q.Size(0) }, `{"size":0}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Query{}
+			tt.build(q)
+			b, err := json.Marshal(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, b)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *Query
+		want  string // expected body of the search request
+	}{
+		{"all documents", nil, `{}`},
+		{"filtered", &Query{filter: []Clause{Term("name", "a")}}, `{"query":{"bool":{"filter":[{"term":{"name":"a"}}]}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/docs/doc/_search" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				b, _ := io.ReadAll(r.Body)
+				body = string(b)
+				fmt.Fprint(w, `{"took":1,"hits":{"total":1,"hits":[{"_id":"1","_source":{"name":"a"}}]}}`)
+			}))
+
+			docs, err := c.Find(context.Background(), tt.query)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body != tt.want {
+				t.Errorf("expected body %s, got %s", tt.want, body)
+			}
+			if len(docs) != 1 || docs[0].ID != "1" || docs[0].Name != "a" {
+				t.Errorf("expected the document 1 with its ID, got %+v", docs)
+			}
+		})
+	}
+}
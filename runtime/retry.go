@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy configures the retries of failed requests, see SetRetryPolicy
+// Requests are retried on errors of the connection and the Statuses. Requests
+// with a method not in Methods are only retried, when they are known to be
+// idempotent, like indexing a document with an ID, or when elasticsearch
+// rejected them with 429 Too Many Requests
+type RetryPolicy struct {
+	MaxAttempts int              // Maximum number of attempts, including the first one
+	Backoff     time.Duration    // Wait before the first retry, doubled for every further retry
+	MaxBackoff  time.Duration    // Maximum wait before a retry, 0 for none
+	Statuses    []int            // HTTP status codes, which are retried
+	Methods     []string         // HTTP methods, which are retried
+	OnRetry     func(RetryEvent) // Called before every retry
+}
+
+// RetryEvent describes a failed attempt, which is retried
+type RetryEvent struct {
+	Method  string
+	URL     string
+	Attempt int           // Number of the failed attempt, starting with 1
+	Status  int           // HTTP status code of the response, 0 when the request failed
+	Err     error         // Error of the request, nil when elasticsearch responded
+	Wait    time.Duration // Wait before the next attempt
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts, which retries 429, 502, 503 and 504 responses
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Statuses:    []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		Methods:     []string{"GET", "HEAD", "PUT", "DELETE"},
+	}
+}
+
+// SetRetryPolicy sets the policy for retrying failed requests, requests aren't retried by default
+func (c *Client[T]) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+type idempotentKey struct{}
+
+// idempotent marks the requests with the context as idempotent, to retry them regardless of their method
+func idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// retryable returns whether the attempt of the request should be retried
+func (p RetryPolicy) retryable(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil || !rewindable(req) {
+		return false
+	}
+	if res != nil && res.StatusCode == http.StatusTooManyRequests && contains(p.Statuses, res.StatusCode) {
+		return true
+	}
+	if !contains(p.Methods, req.Method) && req.Context().Value(idempotentKey{}) == nil {
+		return false
+	}
+	return err != nil || contains(p.Statuses, res.StatusCode)
+}
+
+// temporary returns whether the request failed temporarily, so it's worth to be sent again: it has been
+// rejected with 429 Too Many Requests or a 5xx status, it timed out or the node couldn't be reached
+func temporary(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status == http.StatusTooManyRequests || e.Status >= 500
+	}
+	return errors.Is(err, ErrTimeout) || isConnectionError(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// rewindable returns whether the body of the request can be sent again
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// backoff returns the wait before the retry after the given attempt, starting with 0
+// The wait is doubled for every attempt up to max, with a random jitter of up to the half of it
+// A max of 0 or less doesn't limit the wait
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base << attempt
+	if d>>attempt != base || d < 0 {
+		// overflow
+		d = math.MaxInt64
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func contains[V comparable](list []V, v V) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		base     time.Duration
+		max      time.Duration
+		min, lim time.Duration // expected range of the wait, including the jitter
+	}{
+		{"first attempt", 0, 100 * time.Millisecond, time.Second, 50 * time.Millisecond, 100 * time.Millisecond},
+		{"doubled", 2, 100 * time.Millisecond, time.Second, 200 * time.Millisecond, 400 * time.Millisecond},
+		{"capped", 5, 100 * time.Millisecond, time.Second, 500 * time.Millisecond, time.Second},
+		{"without max", 5, 100 * time.Millisecond, 0, 1600 * time.Millisecond, 3200 * time.Millisecond},
+		{"overflow capped", 70, time.Second, time.Minute, 30 * time.Second, time.Minute},
+		{"overflow without max", 70, time.Second, 0, math.MaxInt64 / 2, math.MaxInt64},
+		{"without backoff", 3, 0, time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				d := backoff(tt.attempt, tt.base, tt.max)
+				if d < tt.min || d > tt.lim {
+					t.Fatalf("expected a wait between %s and %s, got %s", tt.min, tt.lim, d)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name   string
+		method string
+		ctx    context.Context
+		body   io.Reader // a body without GetBody, if it isn't a *strings.Reader
+		status int       // status of the response, 0 for an error of the request
+		want   bool
+	}{
+		{"GET on 503", "GET", nil, nil, http.StatusServiceUnavailable, true},
+		{"GET on 502", "GET", nil, nil, http.StatusBadGateway, true},
+		{"GET on 504", "GET", nil, nil, http.StatusGatewayTimeout, true},
+		{"GET on 500", "GET", nil, nil, http.StatusInternalServerError, false},
+		{"GET on 404", "GET", nil, nil, http.StatusNotFound, false},
+		{"GET on a connection error", "GET", nil, nil, 0, true},
+		{"DELETE on 503", "DELETE", nil, nil, http.StatusServiceUnavailable, true},
+		{"PUT with body on 503", "PUT", nil, strings.NewReader(`{}`), http.StatusServiceUnavailable, true},
+		{"POST on 503", "POST", nil, strings.NewReader(`{}`), http.StatusServiceUnavailable, false},
+		{"POST on a connection error", "POST", nil, strings.NewReader(`{}`), 0, false},
+		{"POST on 429", "POST", nil, strings.NewReader(`{}`), http.StatusTooManyRequests, true},
+		{"idempotent POST on 503", "POST", idempotent(context.Background()), strings.NewReader(`{}`), http.StatusServiceUnavailable, true},
+		{"idempotent POST on a connection error", "POST", idempotent(context.Background()), strings.NewReader(`{}`), 0, true},
+		{"idempotent POST on 400", "POST", idempotent(context.Background()), strings.NewReader(`{}`), http.StatusBadRequest, false},
+		{"body which can't be rewound", "PUT", nil, io.MultiReader(strings.NewReader(`{}`)), http.StatusServiceUnavailable, false},
+		{"body which can't be rewound on 429", "POST", nil, io.MultiReader(strings.NewReader(`{}`)), http.StatusTooManyRequests, false},
+		{"canceled context", "GET", canceled, nil, http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, "/docs/doc/1", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				res    *http.Response
+				reqErr error
+			)
+			if tt.status == 0 {
+				reqErr = errors.New("connection reset by peer")
+			} else {
+				res = &http.Response{StatusCode: tt.status}
+			}
+
+			got := DefaultRetryPolicy().retryable(req, res, reqErr)
+
+			if got != tt.want {
+				t.Errorf("expected retryable %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string // ID of the indexed document
+		statuses []int  // statuses of the attempts, 201 after them
+		attempts int    // expected number of attempts
+		err      error  // expected kind of the error
+	}{
+		{"document with ID", "1", []int{503, 503}, 3, nil},
+		{"document without ID", "", []int{503}, 1, ErrUnavailable},
+		{"document without ID rejected", "", []int{429}, 2, nil},
+		{"attempts exhausted", "1", []int{503, 503, 503}, 3, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				if len(bodies) <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[len(bodies)-1])
+					fmt.Fprint(w, `{"error":{"type":"unavailable"}}`)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"_id":"1","result":"created"}`)
+			}))
+			policy := DefaultRetryPolicy()
+			policy.Backoff = time.Millisecond
+			var events []RetryEvent
+			policy.OnRetry = func(e RetryEvent) {
+				events = append(events, e)
+			}
+			c.SetRetryPolicy(policy)
+
+			_, err := c.Index(context.Background(), &testDoc{ID: tt.id, Name: "retried"})
+
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if len(bodies) != tt.attempts {
+				t.Fatalf("expected %d attempts, got %d", tt.attempts, len(bodies))
+			}
+			if len(events) != tt.attempts-1 {
+				t.Errorf("expected %d retry events, got %d", tt.attempts-1, len(events))
+			}
+			for n, b := range bodies {
+				// the body is rewound for every attempt
+				if b != bodies[0] || !strings.Contains(b, "retried") {
+					t.Errorf("expected body %s of attempt %d, got %s", bodies[0], n+1, b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package runtime
+
+import "encoding/json"
+
+// Hits is the response of a search request
+type Hits[T any] struct {
+	Took     int    `json:"took"`
+	TimedOut bool   `json:"timed_out"`
+	Shards   shards `json:"_shards"`
+	Hits     struct {
+		Total    int      `json:"total"`
+		MaxScore float64  `json:"max_score"`
+		Hits     []Hit[T] `json:"hits"`
+	} `json:"hits"`
+	Error *Error `json:"error"`
+}
+
+// Hit is a single document found by a search request
+type Hit[T any] struct {
+	ID     string            `json:"_id"`
+	Score  float64           `json:"_score"`
+	Source T                 `json:"_source"`
+	Sort   []json.RawMessage `json:"sort,omitempty"`
+}
+
+type shards struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+}
+
+type indexManipulationResponse struct {
+	Acknowledged bool `json:"acknowledged"`
+}
+
+type docResponse struct {
+	ID     string `json:"_id"`
+	Result string `json:"result"`
+	Error  *Error `json:"error"`
+}
@@ -0,0 +1,96 @@
+package slimlastic
+
+import (
+	"go/types"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// searchBoostOption is the option of the es tag, which sets the boost of the field for Search
+const searchBoostOption = "searchBoost"
+
+// searchFields returns the fields the generated Search matches the text against, like
+// "title^2". These are all text fields of the mapping, except those in nested
+// documents, which can't be queried by a multi_match query. The boosts are taken
+// from the searchBoost option of the es tags, the given boosts take precedence
+// and add fields, which aren't text fields
+func searchFields(m *modelStruct, def map[string]interface{}, typeName string, boosts map[string]float64) ([]string, error) {
+	all := map[string]float64{}
+	err := tagBoosts(m.Fields, "", all, map[*types.Struct]bool{m.Struct: true})
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]float64{}
+	mappings, _ := def["mappings"].(map[string]interface{})
+	mapping, _ := mappings[typeName].(map[string]interface{})
+	props, _ := mapping["properties"].(map[string]interface{})
+	textFields(props, "", fields)
+	for f := range fields {
+		if b, ok := all[f]; ok {
+			fields[f] = b
+		}
+	}
+	for f, b := range boosts {
+		fields[f] = b
+	}
+	res := make([]string, 0, len(fields))
+	for f, b := range fields {
+		if b == 1 {
+			res = append(res, f)
+			continue
+		}
+		res = append(res, f+"^"+strconv.FormatFloat(b, 'f', -1, 64))
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// textFields adds the paths of all text fields in the mapping properties to fields
+func textFields(props map[string]interface{}, prefix string, fields map[string]float64) {
+	for name, p := range props {
+		prop, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch prop["type"] {
+		case "text":
+			fields[prefix+name] = 1
+		case "nested":
+			continue
+		}
+		if sub, ok := prop["properties"].(map[string]interface{}); ok {
+			textFields(sub, prefix+name+".", fields)
+		}
+	}
+}
+
+// tagBoosts adds the searchBoost options of the es tags to boosts, keyed by the path of the field
+func tagBoosts(fields []modelField, prefix string, boosts map[string]float64, seen map[*types.Struct]bool) error {
+	for _, f := range fields {
+		tag := parseESTag(f.Tag.Get("es"))
+		if tag.Skip {
+			continue
+		}
+		path := prefix + f.JSONName
+		if v, ok := tag.Options[searchBoostOption]; ok {
+			b, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s of field %s", searchBoostOption, f.Name)
+			}
+			boosts[path] = b
+		}
+		st, ok := elemType(f.Type).Underlying().(*types.Struct)
+		if !ok || seen[st] {
+			continue
+		}
+		seen[st] = true
+		err := tagBoosts(jsonFields(st), path+".", boosts, seen)
+		delete(seen, st)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
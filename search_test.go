@@ -0,0 +1,87 @@
+package slimlastic
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		boosts map[string]float64
+		fields []string
+		err    bool
+	}{
+		{
+			name:   "text fields",
+			src:    "type M struct { Title string `json:\"title\" es:\"text\"`; Body string `json:\"body\" es:\"text,analyzer=german\"`; Tag string `json:\"tag\"`; Count int `json:\"count\"` }",
+			fields: []string{"body", "title"},
+		},
+		{
+			name:   "boosted fields",
+			src:    "type M struct { Title string `json:\"title\" es:\"text,searchBoost=2.5\"`; Body string `json:\"body\" es:\"text,searchBoost=1\"` }",
+			fields: []string{"body", "title^2.5"},
+		},
+		{
+			name:   "boost of a field which isn't text",
+			src:    "type M struct { Tag string `json:\"tag\" es:\"keyword,searchBoost=2\"` }",
+			fields: []string{},
+		},
+		{
+			name:   "given boosts",
+			src:    "type M struct { Title string `json:\"title\" es:\"text,searchBoost=2\"`; Tag string `json:\"tag\"` }",
+			boosts: map[string]float64{"title": 3, "tag": 1},
+			fields: []string{"tag", "title^3"},
+		},
+		{
+			name:   "skipped fields",
+			src:    "type M struct { ID string `json:\"-\" es:\"text\"`; Secret string `json:\"secret\" es:\"-\"`; Title string `json:\"title\" es:\"text\"` }",
+			fields: []string{"title"},
+		},
+		{
+			name:   "embedded structs",
+			src:    "type Base struct { Title string `json:\"title\" es:\"text,searchBoost=2\"` }\ntype M struct { Base; Body string `json:\"body\" es:\"text\"` }",
+			fields: []string{"body", "title^2"},
+		},
+		{
+			name:   "objects",
+			src:    "type Author struct { Name string `json:\"name\" es:\"text,searchBoost=3\"` }\ntype M struct { Author *Author `json:\"author\"`; Editors []Author `json:\"editors\"` }",
+			fields: []string{"author.name^3", "editors.name^3"},
+		},
+		{
+			name:   "nested documents",
+			src:    "type Comment struct { Text string `json:\"text\" es:\"text\"` }\ntype M struct { Comments []Comment `json:\"comments\" es:\"nested\"`; Title string `json:\"title\" es:\"text\"` }",
+			fields: []string{"title"},
+		},
+		{
+			name: "invalid boost",
+			src:  "type M struct { Title string `json:\"title\" es:\"text,searchBoost=high\"` }",
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, tt.src)
+			def, err := indexDefinition(m, "doc", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fields, err := searchFields(m, def, "doc", tt.boosts)
+
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error, got fields %v", fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(fields) != fmt.Sprint(tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
@@ -6,18 +6,27 @@ var clientTemplate = `// Code generated by slimlastic DO NOT EDIT.
 package {{.TargetPackage}}
 
 import (
-{{- range .Imports }}
+{{- range .StdImports }}
 	"{{.}}"
 {{- end }}
+{{ range .Imports }}
+	{{ with index $.ImportNames . }}{{ . }} {{ end }}"{{.}}"
+{{- end }}
 )
 
 {{- if .WithConstructor }}
-// New{{.UppercaseClient}} instantiates a new elasticsearch client
+// {{.Constructor}}{{.Ctx}} instantiates a new elasticsearch client
 // which is dedicated to the struct {{.SourcePackage}}.{{.Model}}
-func new{{.UppercaseClient}}(url string) (*{{.LowercaseClient}}, error) {
-	c := &{{.LowercaseClient}}{}
+func {{.Constructor}}{{.Ctx}}(ctx context.Context, url string, opts ...{{.Client}}Option) (*{{.Client}}, error) {
+	c := &{{.Client}}{}
 	c.Init(url)
-	err := c.EnsureExistingIndex()
+	for _, o := range opts {
+		o(c)
+	}
+	if c.skipIndexCreation {
+		return c, nil
+	}
+	err := c.Client.EnsureExistingIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -25,291 +34,760 @@ func new{{.UppercaseClient}}(url string) (*{{.LowercaseClient}}, error) {
 }
 {{- end}}
 
-func (c *{{.LowercaseClient}}) Init(url string) {
-	url = strings.TrimRight(url, "/")
-	c.http = &http.Client{Timeout: 5 * time.Second}
-	c.indexURL = fmt.Sprintf("%s/{{.IndexName}}", url)
-	c.typeURL =  fmt.Sprintf("%s/{{.IndexName}}/{{.TypeName}}", url)
+// {{.Client}} is the elasticsearch client for {{.ModelWithPrefix}}
+// The methods without a typed ID are provided by the embedded runtime.Client
+type {{.Client}} struct {
+	*runtime.Client[{{.ModelWithPrefix}}]
+	skipIndexCreation bool
 }
 
-func (c *{{.LowercaseClient}}) EnsureExistingIndex() error {
-	indexExists, err := c.IndexExists()
-	if err != nil {
-		return err
-	}
-	if indexExists {
-		return nil
+// Init initializes the client for the elasticsearch nodes at url, which can contain
+// multiple URLs separated by commas, without sending any request
+func (c *{{.Client}}) Init(url string) {
+	c.Client = runtime.NewClient(url, {{.LowercaseClient}}Model, {{.Timeout}})
+}
+
+// {{.Client}}Option configures the {{.Client}} on construction
+type {{.Client}}Option func(*{{.Client}})
+
+// {{.Client}}WithTimeout overrides the default timeout of {{.Timeout}} for requests to elasticsearch
+func {{.Client}}WithTimeout(d time.Duration) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetTimeout(d)
 	}
-	return c.CreateIndex()
 }
 
-type {{.LowercaseClient}} struct {
-	http     *http.Client
-	indexURL string
-	typeURL  string
+// {{.Client}}WithHTTPClient sends the requests with a copy of h, to share its connections with other clients
+// The timeout of the client is kept, when h has none. TLS options like {{.Client}}WithCACertFile are
+// applied to a clone of its transport regardless of the order of the options, see {{.Client}}WithTransport
+func {{.Client}}WithHTTPClient(h *http.Client) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetHTTPClient(h)
+	}
 }
 
-func (c *{{.LowercaseClient}}) Refresh() error {
-	var result struct {
-		Shards struct {
-			Total      int ` + "`" + `json:"total"` + "`" + `
-			Successful int ` + "`" + `json:"successful"` + "`" + `
-			Failed     int ` + "`" + `json:"failed"` + "`" + `
-		} ` + "`" + `json:"_shards"` + "`" + `
+// {{.Client}}WithTransport sends the requests with the transport, like one which adds tracing
+// TLS options like {{.Client}}WithCACertFile are applied to a clone of it regardless of the order of
+// the options. The requests fail, when TLS options are combined with a transport other than *http.Transport
+func {{.Client}}WithTransport(t http.RoundTripper) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetTransport(t)
 	}
-	err := c.doRequest("POST", fmt.Sprintf("%s/_refresh", c.indexURL), nil, &result)
-	if err != nil {
-		return err
+}
+
+// {{.Client}}WithIndexName overrides the index name {{.IndexName}}
+func {{.Client}}WithIndexName(name string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetIndex(name)
 	}
-	if result.Shards.Failed != 0 {
-		return fmt.Errorf("Refreshing of %d shards failed (%d successful; %d in total)", result.Shards.Failed, result.Shards.Successful, result.Shards.Total)
+}
+
+// {{.Client}}WithoutIndexCreation skips the creation of the index by {{.Constructor}}{{.Ctx}}, which then sends no request
+func {{.Client}}WithoutIndexCreation() {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.skipIndexCreation = true
 	}
-	return nil
 }
 
-func (c *{{.LowercaseClient}}) GetOneByID(ID string) (*{{.ModelWithPrefix}}, error) {
-	var response struct {
-		ID     string      ` + "`" + `json:"_id"` + "`" + `
-		Source {{.ModelWithPrefix}} ` + "`" + `json:"_source"` + "`" + `
-		Found  bool        ` + "`" + `json:"found"` + "`" + `
+// {{.Client}}WithVersionedIndex uses the index name as alias of versioned indices like {{.IndexName}}_v1, which are
+// replaced by Reindex{{.Ctx}} without downtime for reads. {{.Constructor}}{{.Ctx}} creates the first version, if the alias doesn't exist
+func {{.Client}}WithVersionedIndex() {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetVersioned()
 	}
-	err := c.doRequest("GET", fmt.Sprintf("%s/%s", c.typeURL, ID), nil, &response)
-	if err != nil {
-		return nil, err
+}
+
+// {{.Client}}WithNodes spreads the requests round-robin over the elasticsearch nodes at the urls
+// Invalid urls are reported by the requests
+func {{.Client}}WithNodes(urls ...string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		_ = c.SetNodes(urls...)
 	}
-	if !response.Found {
-		return nil, errtypes.NewNotFoundf("{{.ModelWithPrefix}} with id %d not found", ID)
+}
+
+// {{.Client}}WithResurrectTimeout skips a node for d after a connection error, doubled for every consecutive one
+func {{.Client}}WithResurrectTimeout(d time.Duration) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetResurrectTimeout(d)
 	}
-	response.Source.ID = response.ID
-	return &response.Source, nil
 }
 
-func {{.LowercaseModel}}FromElasticsearchHit(hit {{.LowercaseClient}}Hit) {{.ModelWithPrefix}} {
-	hit.Source.ID = hit.ID
-	return hit.Source
+// {{.Client}}WithSniffInterval discovers the nodes of the cluster, when the last discovery is older than d
+func {{.Client}}WithSniffInterval(d time.Duration) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetSniffInterval(d)
+	}
 }
 
-func {{.LowercaseModel}}sFromElasticsearchHits(hits []{{.LowercaseClient}}Hit) []{{.ModelWithPrefix}} {
-	res := make([]{{.ModelWithPrefix}}, len(hits))
-	for n, h := range hits {
-		res[n] = {{.LowercaseModel}}FromElasticsearchHit(h)
+// {{.Client}}WithBasicAuth authenticates the requests with basic auth
+func {{.Client}}WithBasicAuth(user, password string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetBasicAuth(user, password)
 	}
-	return res
 }
 
-func (c *{{.LowercaseClient}}) GetList(offset, limit int) ([]{{.ModelWithPrefix}}, error) {
-	return c.DoListRequest(strings.NewReader(fmt.Sprintf(` + "`" + `{"from":%d,"size":%d}` + "`" + `, offset, limit)))
+// {{.Client}}WithAPIKey authenticates the requests with the encoded API key
+func {{.Client}}WithAPIKey(key string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetAPIKey(key)
+	}
 }
 
-func (c *{{.LowercaseClient}}) DoListRequest(body io.Reader, opts ...{{.LowercaseClient}}ListRequestOpt) ([]{{.ModelWithPrefix}}, error) {
-	var cfg {{.LowercaseClient}}ListRequestOptions
-	for _, o := range opts {
-		o(&cfg)
+// {{.Client}}WithBearerToken authenticates the requests with the bearer token
+func {{.Client}}WithBearerToken(token string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetBearerToken(token)
 	}
-	var result {{.LowercaseClient}}Hits
-	err := c.doRequest("GET", fmt.Sprintf("%s/_search", c.typeURL), body, &result)
-	if err != nil {
-		return nil, err
+}
+
+// {{.Client}}WithCACertFile trusts only the certificate authorities of the PEM file
+// Errors are reported by the requests
+func {{.Client}}WithCACertFile(path string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		_ = c.SetCACertFile(path)
 	}
-	if result.Error != nil {
-		return nil, fmt.Errorf("Error in elasticsearch: %s, caused by %#v", result.Error.Reason, result.Error.CausedBy)
+}
+
+// {{.Client}}WithClientCertFiles authenticates the client with the certificate and key of the PEM files
+// Errors are reported by the requests
+func {{.Client}}WithClientCertFiles(certFile, keyFile string) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		_ = c.SetClientCertFiles(certFile, keyFile)
 	}
-	if cfg.total != nil {
-		*cfg.total = uint32(result.Hits.Total)
+}
+
+// {{.Client}}WithInsecureSkipVerify disables the verification of the certificates of the nodes, only for development
+func {{.Client}}WithInsecureSkipVerify() {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		_ = c.SetInsecureSkipVerify(true)
 	}
-	return {{.LowercaseModel}}sFromElasticsearchHits(result.Hits.Hits), nil
 }
 
-type {{.LowercaseClient}}ListRequestOptions struct {
-	total *uint32
+// {{.Client}}WithMiddleware wraps the sending of every request with the middlewares, like runtime.Logging
+func {{.Client}}WithMiddleware(mw ...runtime.Middleware) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.Use(mw...)
+	}
 }
 
-type {{.LowercaseClient}}ListRequestOpt func(*{{.LowercaseClient}}ListRequestOptions)
+// {{.Client}}WithInstrumentation reports every operation of the client to the instrumentations,
+// like those of the runtime/prommetrics and runtime/oteltrace packages
+func {{.Client}}WithInstrumentation(in ...runtime.Instrumentation) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.Instrument(in...)
+	}
+}
 
-func {{.LowercaseClient}}WithTotal(t *uint32) {{.LowercaseClient}}ListRequestOpt {
-	return func(o *{{.LowercaseClient}}ListRequestOptions) {
-		o.total = t
+// {{.Client}}WithRetryPolicy retries failed requests according to the policy, see runtime.DefaultRetryPolicy
+func {{.Client}}WithRetryPolicy(p runtime.RetryPolicy) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetRetryPolicy(p)
 	}
 }
 
+// WithTimeout returns a copy of the client, which uses the given timeout for its requests
+// It's meant for single calls which need a different deadline, like
+// c.WithTimeout(10 * time.Minute).RecreateIndex{{.Ctx}}(ctx)
+func (c *{{.Client}}) WithTimeout(d time.Duration) *{{.Client}} {
+	return &{{.Client}}{Client: c.Client.WithTimeout(d), skipIndexCreation: c.skipIndexCreation}
+}
 
-// Index creates a new {{.ModelWithPrefix}} in elasticsearch
-// When the ID of the {{.Model}} is set, it updates the {{.Model}}
-// The first return value indicates, whether a new records has been created or not
-func (c *{{.LowercaseClient}}) Index(m *{{.ModelWithPrefix}}, opts ...{{.LowercaseModel}}ElasticsearchIndexOption) (bool, error) {
-	body := &bytes.Buffer{}
-	err := json.NewEncoder(body).Encode(m)
-	if err != nil {
-		return false, err
-	}
-	cfg := {{.LowercaseModel}}ElasticsearchIndexConfig{Refresh: "false"}
-	for _, o := range opts {
-		o(&cfg)
-	}
-	var response {{.LowercaseClient}}DocResponse
-	err = c.doRequest("POST", fmt.Sprintf("%s/%s?refresh=%s", c.typeURL, m.ID, cfg.Refresh), body, &response)
-	if err != nil {
-		return false, err
-	}
-	if response.Error != nil {
-		return false,fmt.Errorf("%#v", response.Error)
-	}
-	if response.ID == "" || (response.Result != "updated" && response.Result != "created") {
-		// if this case happens, please report with furhter information to hello@frederikvosberg.de to implement a better error handling
-		return false, errors.New("indexing of document in elasticsearch failed")
-	}
-	m.ID = response.ID
-	return response.Result == "created", nil
+// GetOneByID{{.Ctx}} returns the {{.ModelWithPrefix}} with the given ID
+func (c *{{.Client}}) GetOneByID{{.Ctx}}(ctx context.Context, id {{.IDType}}) (*{{.ModelWithPrefix}}, error) {
+	return c.Client.GetOneByID(ctx, {{.LowercaseClient}}IDToString(id))
 }
 
-type {{.LowercaseModel}}ElasticsearchIndexOption func(*{{.LowercaseModel}}ElasticsearchIndexConfig)
+// DeleteOneByID{{.Ctx}} deletes a {{.ModelWithPrefix}} in elasticsearch, given its ID
+func (c *{{.Client}}) DeleteOneByID{{.Ctx}}(ctx context.Context, id {{.IDType}}) error {
+	return c.Client.DeleteOneByID(ctx, {{.LowercaseClient}}IDToString(id))
+}
+
+// {{.Client}}Hit is a {{.ModelWithPrefix}} found by Search{{.Ctx}}, with its score
+type {{.Client}}Hit = runtime.Hit[{{.ModelWithPrefix}}]
+
+type {{.Client}}ListRequestOptions = runtime.ListOptions
+
+type {{.Client}}ListRequestOpt = runtime.ListOption
 
-type {{.LowercaseModel}}ElasticsearchIndexConfig struct {
-	Refresh string
+func {{.Client}}WithTotal(t *uint32) {{.Client}}ListRequestOpt {
+	return runtime.WithTotal(t)
 }
 
-// ForceReceiptIndexRefresh forces the immediate refresh after indexing
-// it's used as an {{.LowercaseModel}}ElasticsearchIndexOption param to {{.LowercaseClient}}.Index
-func Force{{.Model}}IndexRefresh(cfg *{{.LowercaseModel}}ElasticsearchIndexConfig) {
-	cfg.Refresh = "true"
+type {{.Client}}IndexOption = runtime.IndexOption
+
+type {{.Client}}IndexConfig = runtime.IndexConfig
+
+// {{.Client}}ForceIndexRefresh forces the immediate refresh after indexing
+// it's used as an {{.Client}}IndexOption param to {{.Client}}.Index{{.Ctx}}
+func {{.Client}}ForceIndexRefresh(cfg *{{.Client}}IndexConfig) {
+	runtime.ForceRefresh(cfg)
 }
+{{- if .DefaultName }}
 
-// DeleteOneByID deletes a {{.ModelWithPrefix}} in elasticsearch, given its ID
-func (c *{{.LowercaseClient}}) DeleteOneByID(id string) error {
-	var response {{.LowercaseClient}}DocResponse
-	err := c.doRequest("DELETE", fmt.Sprintf("%s/%s", c.typeURL, id), nil, &response)
-	if err != nil {
-		return err
-	}
-	if response.Error != nil {
-		return fmt.Errorf("%#v", response.Error)
-	}
-	if response.Result != "deleted" {
-		// if this case happens, please report with furhter information to hello@frederikvosberg.de to implement a better error handling
-		return fmt.Errorf("deletion of document in elasticsearch failed, result was %q", response.Result)
-	}
-	return nil
+// Deprecated: use {{.Client}}IndexOption
+type {{.ModelPrefix}}ElasticsearchIndexOption = runtime.IndexOption
+
+// Deprecated: use {{.Client}}IndexConfig
+type {{.ModelPrefix}}ElasticsearchIndexConfig = runtime.IndexConfig
+
+// Force{{.Model}}IndexRefresh forces the immediate refresh after indexing
+//
+// Deprecated: use {{.Client}}ForceIndexRefresh
+func Force{{.Model}}IndexRefresh(cfg *{{.ModelPrefix}}ElasticsearchIndexConfig) {
+	runtime.ForceRefresh(cfg)
 }
+{{- end }}
 
-func (c *{{.LowercaseClient}}) RecreateIndex() error {
-	_, err := c.DeleteIndex()
-	if err != nil {
-		return err
+// BulkDelete{{.Ctx}} deletes the {{.ModelWithPrefix}}s with the given IDs with the _bulk API
+// The failed deletions are reported by the result, see {{.Client}}BulkResult.Err
+func (c *{{.Client}}) BulkDelete{{.Ctx}}(ctx context.Context, ids []{{.IDType}}, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	strIDs := make([]string, len(ids))
+	for n, id := range ids {
+		strIDs[n] = {{.LowercaseClient}}IDToString(id)
 	}
-	return c.CreateIndex()
+	return c.Client.BulkDelete(ctx, strIDs, opts...)
 }
 
-func (c *{{.LowercaseClient}}) IndexExists() (bool, error) {
-	req, err := c.newRequest("HEAD", c.indexURL, nil)
-	if err != nil {
-		return false, err
-	}
-	res, err := c.http.Do(req)
-	if err != nil {
-		return false, err
+// {{.Client}}BulkOp is an operation of Bulk{{.Ctx}}, see {{.Client}}IndexOp and {{.Client}}DeleteOp
+type {{.Client}}BulkOp = runtime.BulkOp[{{.ModelWithPrefix}}]
+
+// {{.Client}}IndexOp creates or updates the {{.ModelWithPrefix}} in Bulk{{.Ctx}}
+func {{.Client}}IndexOp(m *{{.ModelWithPrefix}}) {{.Client}}BulkOp {
+	return runtime.IndexOp(m)
+}
+
+// {{.Client}}DeleteOp deletes the {{.ModelWithPrefix}} with the given ID in Bulk{{.Ctx}}
+func {{.Client}}DeleteOp(id {{.IDType}}) {{.Client}}BulkOp {
+	return runtime.DeleteOp[{{.ModelWithPrefix}}]({{.LowercaseClient}}IDToString(id))
+}
+
+type {{.Client}}BulkOption = runtime.BulkOption
+
+type {{.Client}}BulkResult = runtime.BulkResult
+
+// {{.Client}}WithBulkMaxDocs splits bulk operations into requests of at most n operations
+func {{.Client}}WithBulkMaxDocs(n int) {{.Client}}BulkOption {
+	return runtime.WithBulkMaxDocs(n)
+}
+
+// {{.Client}}WithBulkMaxBytes splits bulk operations into requests with bodies of at most n bytes
+func {{.Client}}WithBulkMaxBytes(n int) {{.Client}}BulkOption {
+	return runtime.WithBulkMaxBytes(n)
+}
+
+// {{.Client}}ForceBulkRefresh forces the immediate refresh after each bulk request
+func {{.Client}}ForceBulkRefresh(cfg *runtime.BulkConfig) {
+	runtime.ForceBulkRefresh(cfg)
+}
+
+// {{.Client}}BulkProcessor indexes and deletes {{.ModelWithPrefix}}s in the background, see
+// {{.Client}}.NewBulkProcessor. Deletions by ID are added with {{.Client}}DeleteOp
+type {{.Client}}BulkProcessor = runtime.BulkProcessor[{{.ModelWithPrefix}}]
+
+type {{.Client}}BulkProcessorOption = runtime.BulkProcessorOption[{{.ModelWithPrefix}}]
+
+// {{.Client}}WithBulkWorkers sets the number of concurrent bulk requests of the {{.Client}}BulkProcessor
+func {{.Client}}WithBulkWorkers(n int) {{.Client}}BulkProcessorOption {
+	return runtime.WithBulkWorkers[{{.ModelWithPrefix}}](n)
+}
+
+// {{.Client}}WithFlushInterval flushes the buffered operations of the {{.Client}}BulkProcessor at least every d
+func {{.Client}}WithFlushInterval(d time.Duration) {{.Client}}BulkProcessorOption {
+	return runtime.WithFlushInterval[{{.ModelWithPrefix}}](d)
+}
+
+// {{.Client}}WithFlushDocs flushes the buffered operations of the {{.Client}}BulkProcessor, when there are n of them
+func {{.Client}}WithFlushDocs(n int) {{.Client}}BulkProcessorOption {
+	return runtime.WithFlushDocs[{{.ModelWithPrefix}}](n)
+}
+
+// {{.Client}}WithFlushBytes flushes the buffered operations of the {{.Client}}BulkProcessor, when they reach n bytes
+func {{.Client}}WithFlushBytes(n int) {{.Client}}BulkProcessorOption {
+	return runtime.WithFlushBytes[{{.ModelWithPrefix}}](n)
+}
+
+// {{.Client}}WithBulkRetries retries failed operations of the {{.Client}}BulkProcessor n times with an exponential backoff
+func {{.Client}}WithBulkRetries(n int, backoff, maxBackoff time.Duration) {{.Client}}BulkProcessorOption {
+	return runtime.WithBulkRetries[{{.ModelWithPrefix}}](n, backoff, maxBackoff)
+}
+
+// {{.Client}}OnBulkFailure reports the operations of the {{.Client}}BulkProcessor, which failed finally, to f
+func {{.Client}}OnBulkFailure(f func([]{{.Client}}BulkOp, error)) {{.Client}}BulkProcessorOption {
+	return runtime.OnBulkFailure(f)
+}
+
+// Find{{.Ctx}} returns the {{.ModelWithPrefix}}s matching the query
+func (c *{{.Client}}) Find{{.Ctx}}(ctx context.Context, q *{{.Client}}QueryBuilder, opts ...{{.Client}}ListRequestOpt) ([]{{.ModelWithPrefix}}, error) {
+	return c.Client.Find(ctx, q.Query(), opts...)
+}
+
+// GetPage{{.Ctx}} returns size {{.ModelWithPrefix}}s matching the query, starting after the {{.ModelWithPrefix}} the
+// cursor points to, or at the first one for an empty cursor. The {{.ModelWithPrefix}}s are sorted by the
+// sorts of the query and their ID as tiebreaker. The cursor of the next page is signed, see {{.Client}}WithCursorKey
+func (c *{{.Client}}) GetPage{{.Ctx}}(ctx context.Context, q *{{.Client}}QueryBuilder, cursor string, size int) ({{.Client}}Page, error) {
+	return c.Client.GetPage(ctx, q.Query(), cursor, size)
+}
+
+// {{.Client}}Page is a page of {{.ModelWithPrefix}}s returned by GetPage{{.Ctx}}
+type {{.Client}}Page = runtime.Page[{{.ModelWithPrefix}}]
+
+// {{.Client}}WithCursorKey sets the key, which signs the cursors of GetPage{{.Ctx}}
+// Without it, the cursors are only valid for the running process
+func {{.Client}}WithCursorKey(key []byte) {{.Client}}Option {
+	return func(c *{{.Client}}) {
+		c.SetCursorKey(key)
 	}
-	res.Body.Close()
-	if res.StatusCode != 200 && res.StatusCode != 404 {
-		return false, fmt.Errorf("status code should be 200 or 404 on checking existence of \"{{.ModelWithPrefix}}\" index, but was %d", res.StatusCode)
+}
+
+// All{{.Ctx}} returns an iterator over all {{.ModelWithPrefix}}s matching the query, using the scroll API
+// A nil query matches all {{.ModelWithPrefix}}s. The iterator has to be closed, when it's not iterated until the end
+func (c *{{.Client}}) All{{.Ctx}}(ctx context.Context, q *{{.Client}}QueryBuilder, opts ...{{.Client}}ScrollOption) *{{.Client}}Iterator {
+	return c.Client.All(ctx, q.Query(), opts...)
+}
+
+// Each{{.Ctx}} calls f for every {{.ModelWithPrefix}} matching the query, until f returns an error, see All{{.Ctx}}
+func (c *{{.Client}}) Each{{.Ctx}}(ctx context.Context, q *{{.Client}}QueryBuilder, f func(*{{.ModelWithPrefix}}) error, opts ...{{.Client}}ScrollOption) error {
+	return c.Client.Each(ctx, q.Query(), f, opts...)
+}
+
+// {{.Client}}Iterator iterates over {{.ModelWithPrefix}}s, see All{{.Ctx}}
+type {{.Client}}Iterator = runtime.Iterator[{{.ModelWithPrefix}}]
+
+type {{.Client}}ScrollOption = runtime.ScrollOption
+
+// {{.Client}}WithScrollSize fetches n {{.ModelWithPrefix}}s per request of All{{.Ctx}} and Each{{.Ctx}}
+func {{.Client}}WithScrollSize(n int) {{.Client}}ScrollOption {
+	return runtime.WithScrollSize(n)
+}
+
+// {{.Client}}WithScrollKeepAlive keeps the scroll context of All{{.Ctx}} and Each{{.Ctx}} alive for d between two requests
+func {{.Client}}WithScrollKeepAlive(d time.Duration) {{.Client}}ScrollOption {
+	return runtime.WithScrollKeepAlive(d)
+}
+
+type {{.Client}}ReindexOption = runtime.ReindexOption[{{.ModelWithPrefix}}]
+
+type {{.Client}}ReindexResult = runtime.ReindexResult
+
+// {{.Client}}IndexVersion is an index version behind the alias, see {{.Client}}WithVersionedIndex
+type {{.Client}}IndexVersion = runtime.IndexVersion
+
+// {{.Client}}WithKeepIndexVersions keeps n old index versions for RollbackIndex{{.Ctx}}, older ones are deleted by Reindex{{.Ctx}}
+func {{.Client}}WithKeepIndexVersions(n int) {{.Client}}ReindexOption {
+	return runtime.WithKeepIndexVersions[{{.ModelWithPrefix}}](n)
+}
+
+// {{.Client}}WithReindexLoader fills the new index version of Reindex{{.Ctx}} with f instead of copying the
+// {{.ModelWithPrefix}}s of the live one. The client passed to f addresses the new index version
+func {{.Client}}WithReindexLoader(f func(ctx context.Context, c *{{.Client}}) error) {{.Client}}ReindexOption {
+	return runtime.WithReindexLoader(func(ctx context.Context, c *runtime.Client[{{.ModelWithPrefix}}]) error {
+		return f(ctx, &{{.Client}}{Client: c, skipIndexCreation: true})
+	})
+}
+
+// {{.Client}}Field is the path of a field of {{.ModelWithPrefix}} in elasticsearch
+type {{.Client}}Field string
+
+// paths of the fields of {{.ModelWithPrefix}}, including those of nested structs
+const (
+{{- range .FieldPaths }}
+	{{$.Client}}Field{{.Method}} {{$.Client}}Field = "{{.Path}}"
+{{- end }}
+)
+
+// String returns the path of the field
+func (f {{.Client}}Field) String() string {
+	return string(f)
+}
+
+// NestedPath returns the path of the nested document containing the field, which
+// has to be queried by a nested query, or an empty string, if there is none
+func (f {{.Client}}Field) NestedPath() {{.Client}}Field {
+	switch f {
+{{- range .FieldPaths }}{{ if .Nested }}
+	case {{$.Client}}Field{{.Method}}:
+		return {{$.Client}}Field{{.Nested}}
+{{- end }}{{ end }}
 	}
-	return res.StatusCode == 200, nil
+	return ""
 }
 
-func (c *{{.LowercaseClient}}) DeleteIndex() (bool, error) {
-	var response {{.LowercaseClient}}IndexManipulationResponse
-	err := c.doRequest("DELETE", c.indexURL, nil, response)
-	if err != nil {
-		return false, err
+// {{.Client}}QueryBuilder builds a query for {{.ModelWithPrefix}}s, see {{.Client}}.Find{{.Ctx}}
+type {{.Client}}QueryBuilder struct {
+	q runtime.Query
+}
+
+// {{.Client}}Query starts a new query for {{.ModelWithPrefix}}s
+func {{.Client}}Query() *{{.Client}}QueryBuilder {
+	return &{{.Client}}QueryBuilder{}
+}
+
+// Query returns the built query, which can be encoded to JSON for DoListRequest{{.Ctx}}
+// A nil builder returns a nil query, which matches all {{.ModelWithPrefix}}s
+func (b *{{.Client}}QueryBuilder) Query() *runtime.Query {
+	if b == nil {
+		return nil
 	}
-	return response.Acknowledged, nil
+	return &b.q
 }
 
-func (c *{{.LowercaseClient}}) CreateIndex() error {
-	var response {{.LowercaseClient}}IndexManipulationResponse
-	err := c.doRequest("PUT", c.indexURL, strings.NewReader({{.LowercaseClient}}IndexDefinition), &response)
-	if err != nil {
-		return err
+// SortBy sorts the {{.ModelWithPrefix}}s by the field in the order runtime.Asc or runtime.Desc, sorts are applied in the order they are added
+func (b *{{.Client}}QueryBuilder) SortBy(f {{.Client}}Field, o runtime.SortOrder) *{{.Client}}QueryBuilder {
+	b.q.Sort(string(f), o)
+	return b
+}
+
+// From sets the offset of the first {{.ModelWithPrefix}}
+func (b *{{.Client}}QueryBuilder) From(n int) *{{.Client}}QueryBuilder {
+	b.q.From(n)
+	return b
+}
+
+// Size sets the maximum number of {{.ModelWithPrefix}}s
+func (b *{{.Client}}QueryBuilder) Size(n int) *{{.Client}}QueryBuilder {
+	b.q.Size(n)
+	return b
+}
+
+func (b *{{.Client}}QueryBuilder) add(nested string, must bool, c runtime.Clause) *{{.Client}}QueryBuilder {
+	if nested != "" {
+		c = runtime.Nested(nested, c)
 	}
-	if !response.Acknowledged {
-		return fmt.Errorf("creation of index not acknowledged: %#v", response.Error)
+	if must {
+		b.q.Must(c)
+	} else {
+		b.q.Filter(c)
 	}
-	return nil
+	return b
+}
+{{- range .QueryFields }}
+{{ if eq .Kind "match" }}
+// {{.Method}}Matches matches {{$.ModelWithPrefix}}s, whose {{.Path}} matches the text
+func (b *{{$.Client}}QueryBuilder) {{.Method}}Matches(txt string) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", true, runtime.Match("{{.Path}}", txt))
+}
+{{- else }}
+// {{.Method}}Equals matches {{$.ModelWithPrefix}}s, whose {{.Path}} is v
+func (b *{{$.Client}}QueryBuilder) {{.Method}}Equals(v {{.Type}}) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", false, runtime.Term("{{.Path}}", v))
 }
+{{- end }}
+{{- if eq .Kind "term" }}
 
-func (c *{{.LowercaseClient}}) newRequest(method, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, err
+// {{.Method}}In matches {{$.ModelWithPrefix}}s, whose {{.Path}} is one of the values
+func (b *{{$.Client}}QueryBuilder) {{.Method}}In(v ...{{.Type}}) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", false, runtime.Terms("{{.Path}}", v...))
+}
+{{- end }}
+{{- if eq .Kind "range" }}
+
+// {{.Method}}Between matches {{$.ModelWithPrefix}}s, whose {{.Path}} is between from and to, both inclusive
+func (b *{{$.Client}}QueryBuilder) {{.Method}}Between(from, to {{.Type}}) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", false, runtime.Between("{{.Path}}", from, to))
+}
+
+// {{.Method}}GreaterThan matches {{$.ModelWithPrefix}}s, whose {{.Path}} is greater than v
+func (b *{{$.Client}}QueryBuilder) {{.Method}}GreaterThan(v {{.Type}}) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", false, runtime.Range("{{.Path}}", runtime.Gt, v))
+}
+
+// {{.Method}}LessThan matches {{$.ModelWithPrefix}}s, whose {{.Path}} is less than v
+func (b *{{$.Client}}QueryBuilder) {{.Method}}LessThan(v {{.Type}}) *{{$.Client}}QueryBuilder {
+	return b.add("{{.Nested}}", false, runtime.Range("{{.Path}}", runtime.Lt, v))
+}
+{{- end }}
+{{- end }}
+
+// {{.LowercaseClient}}IDToString converts the ID of a {{.ModelWithPrefix}} to the elasticsearch _id
+{{- if eq .IDKind "string" }}
+// The zero value is converted to an empty string, to let elasticsearch generate an ID
+{{- else }}
+// The zero value is converted to an empty string, which is rejected for new {{.ModelWithPrefix}}s,
+// because the IDs generated by elasticsearch can't be converted to {{.IDType}}
+{{- end }}
+func {{.LowercaseClient}}IDToString(id {{.IDType}}) string {
+{{- if eq .IDKind "string" }}
+	return string(id)
+{{- else }}
+{{- if .IDComparable }}
+	var zero {{.IDType}}
+	if id == zero {
+		return ""
 	}
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	return req, nil
+{{- end }}
+{{- if eq .IDKind "int" }}
+	return strconv.FormatInt(int64(id), 10)
+{{- else if eq .IDKind "uint" }}
+	return strconv.FormatUint(uint64(id), 10)
+{{- else }}
+	return id.String()
+{{- end }}
+{{- end }}
 }
 
-func (c *{{.LowercaseClient}}) doRequest(method, url string, body io.Reader, response interface{}) error {
-	req, err := c.newRequest(method, url, body)
+// {{.LowercaseClient}}IDFromString converts the elasticsearch _id to the ID of a {{.ModelWithPrefix}}
+func {{.LowercaseClient}}IDFromString(s string) ({{.IDType}}, error) {
+{{- if eq .IDKind "string" }}
+	return {{.IDType}}(s), nil
+{{- else if eq .IDKind "int" }}
+	id, err := strconv.ParseInt(s, 10, {{.IDBits}})
 	if err != nil {
-		return err
+		return 0, errors.Wrapf(err, "invalid {{.ModelWithPrefix}} ID %q", s)
 	}
-	res, err := c.http.Do(req)
+	return {{.IDType}}(id), nil
+{{- else if eq .IDKind "uint" }}
+	id, err := strconv.ParseUint(s, 10, {{.IDBits}})
 	if err != nil {
-		return err
+		return 0, errors.Wrapf(err, "invalid {{.ModelWithPrefix}} ID %q", s)
 	}
-	defer res.Body.Close()
-	err = json.NewDecoder(res.Body).Decode(&response)
+	return {{.IDType}}(id), nil
+{{- else }}
+	var id {{.IDType}}
+	err := id.UnmarshalText([]byte(s))
 	if err != nil {
-		return errors.Wrap(err, "couldn't decode JSON response")
+		return id, errors.Wrapf(err, "invalid {{.ModelWithPrefix}} ID %q", s)
 	}
-	return nil
+	return id, nil
+{{- end }}
 }
 
-var {{.LowercaseClient}}IndexDefinition = ` + "`{{.IndexDefinition}}`" + `
+var {{.LowercaseClient}}Model = runtime.Model[{{.ModelWithPrefix}}]{
+	Index:           "{{.IndexName}}",
+	Type:            "{{.TypeName}}",
+	IndexDefinition: {{.LowercaseClient}}IndexDefinition,
+	SearchFields:    []string{ {{- range $i, $f := .SearchFields }}{{ if $i }}, {{ end }}"{{ $f }}"{{ end -}} },
+	IDPath:          "{{.IDPath}}",
+{{- if .StoreID }}
+	StoreID: true,
+{{- end }}
+	ID: func(m *{{.ModelWithPrefix}}) string {
+		return {{.LowercaseClient}}IDToString(m.{{.IDField}})
+	},
+	SetID: func(m *{{.ModelWithPrefix}}, id string) error {
+		var err error
+		m.{{.IDField}}, err = {{.LowercaseClient}}IDFromString(id)
+		return err
+	},
+{{- if eq .IDKind "string" }}
+	GeneratedIDs: true,
+{{- end }}
+}
+
+{{- if .LegacyMethods }}
+{{ if .WithConstructor }}
+// {{.Constructor}} calls {{.Constructor}}Context with the background context
+func {{.Constructor}}(url string, opts ...{{.Client}}Option) (*{{.Client}}, error) {
+	return {{.Constructor}}Context(context.Background(), url, opts...)
+}
+{{ end }}
+// EnsureExistingIndexContext creates the index, if it doesn't exist
+func (c *{{.Client}}) EnsureExistingIndexContext(ctx context.Context) error {
+	return c.Client.EnsureExistingIndex(ctx)
+}
 
-type {{.LowercaseClient}}IndexManipulationResponse struct {
-	Acknowledged bool         ` + "`" + `json:"acknowledged"` + "`" + `
-	Status       int          ` + "`" + `json:"status"` + "`" + `
-	Error        elasticError ` + "`" + `json:"error"` + "`" + `
+// EnsureExistingIndex calls EnsureExistingIndexContext with the background context
+func (c *{{.Client}}) EnsureExistingIndex() error {
+	return c.EnsureExistingIndexContext(context.Background())
 }
 
-type {{.LowercaseClient}}Error struct {
-	Type      string         ` + "`" + `json:"type"` + "`" + `
-	Reason    string         ` + "`" + `json:"reason"` + "`" + `
-	CausedBy  *elasticError  ` + "`" + `json:"caused_by"` + "`" + `
-	RootCause []elasticError ` + "`" + `json:"root_cause"` + "`" + `
+// RefreshContext refreshes the index
+func (c *{{.Client}}) RefreshContext(ctx context.Context) error {
+	return c.Client.Refresh(ctx)
 }
 
-{{- if not .PreventCommonCode }}
-type elasticError struct {
-	Type      string         ` + "`" + `json:"type"` + "`" + `
-	Reason    string         ` + "`" + `json:"reason"` + "`" + `
-	CausedBy  *elasticError  ` + "`" + `json:"caused_by"` + "`" + `
-	RootCause []elasticError ` + "`" + `json:"root_cause"` + "`" + `
+// Refresh calls RefreshContext with the background context
+func (c *{{.Client}}) Refresh() error {
+	return c.RefreshContext(context.Background())
+}
+
+// GetOneByID calls GetOneByIDContext with the background context
+func (c *{{.Client}}) GetOneByID(id {{.IDType}}) (*{{.ModelWithPrefix}}, error) {
+	return c.GetOneByIDContext(context.Background(), id)
+}
+
+// GetListContext returns the {{.ModelWithPrefix}}s paginated by offset and limit
+func (c *{{.Client}}) GetListContext(ctx context.Context, offset, limit int) ([]{{.ModelWithPrefix}}, error) {
+	return c.Client.GetList(ctx, offset, limit)
+}
+
+// GetList calls GetListContext with the background context
+func (c *{{.Client}}) GetList(offset, limit int) ([]{{.ModelWithPrefix}}, error) {
+	return c.GetListContext(context.Background(), offset, limit)
+}
+
+// DoListRequestContext sends the search request body and returns the found {{.ModelWithPrefix}}s
+func (c *{{.Client}}) DoListRequestContext(ctx context.Context, body io.Reader, opts ...{{.Client}}ListRequestOpt) ([]{{.ModelWithPrefix}}, error) {
+	return c.Client.DoListRequest(ctx, body, opts...)
+}
+
+// DoListRequest calls DoListRequestContext with the background context
+func (c *{{.Client}}) DoListRequest(body io.Reader, opts ...{{.Client}}ListRequestOpt) ([]{{.ModelWithPrefix}}, error) {
+	return c.DoListRequestContext(context.Background(), body, opts...)
+}
+
+// SearchContext runs a full text search for txt over the text fields of {{.ModelWithPrefix}}
+func (c *{{.Client}}) SearchContext(ctx context.Context, txt string, offset, limit int, opts ...{{.Client}}ListRequestOpt) ([]{{.Client}}Hit, error) {
+	return c.Client.Search(ctx, txt, offset, limit, opts...)
+}
+
+// Search calls SearchContext with the background context
+func (c *{{.Client}}) Search(txt string, offset, limit int, opts ...{{.Client}}ListRequestOpt) ([]{{.Client}}Hit, error) {
+	return c.SearchContext(context.Background(), txt, offset, limit, opts...)
+}
+
+// Find calls FindContext with the background context
+func (c *{{.Client}}) Find(q *{{.Client}}QueryBuilder, opts ...{{.Client}}ListRequestOpt) ([]{{.ModelWithPrefix}}, error) {
+	return c.FindContext(context.Background(), q, opts...)
+}
+
+// GetPage calls GetPageContext with the background context
+func (c *{{.Client}}) GetPage(q *{{.Client}}QueryBuilder, cursor string, size int) ({{.Client}}Page, error) {
+	return c.GetPageContext(context.Background(), q, cursor, size)
+}
+
+// All calls AllContext with the background context
+func (c *{{.Client}}) All(q *{{.Client}}QueryBuilder, opts ...{{.Client}}ScrollOption) *{{.Client}}Iterator {
+	return c.AllContext(context.Background(), q, opts...)
+}
+
+// Each calls EachContext with the background context
+func (c *{{.Client}}) Each(q *{{.Client}}QueryBuilder, f func(*{{.ModelWithPrefix}}) error, opts ...{{.Client}}ScrollOption) error {
+	return c.EachContext(context.Background(), q, f, opts...)
+}
+
+// IndexContext creates or updates a {{.ModelWithPrefix}} in elasticsearch
+func (c *{{.Client}}) IndexContext(ctx context.Context, m *{{.ModelWithPrefix}}, opts ...{{.Client}}IndexOption) (bool, error) {
+	return c.Client.Index(ctx, m, opts...)
+}
+
+// Index calls IndexContext with the background context
+func (c *{{.Client}}) Index(m *{{.ModelWithPrefix}}, opts ...{{.Client}}IndexOption) (bool, error) {
+	return c.IndexContext(context.Background(), m, opts...)
+}
+
+// BulkContext sends the operations to the _bulk API
+func (c *{{.Client}}) BulkContext(ctx context.Context, ops []{{.Client}}BulkOp, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	return c.Client.Bulk(ctx, ops, opts...)
+}
+
+// Bulk calls BulkContext with the background context
+func (c *{{.Client}}) Bulk(ops []{{.Client}}BulkOp, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	return c.BulkContext(context.Background(), ops, opts...)
+}
+
+// BulkIndexContext creates or updates the {{.ModelWithPrefix}}s with the _bulk API
+func (c *{{.Client}}) BulkIndexContext(ctx context.Context, ms []*{{.ModelWithPrefix}}, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	return c.Client.BulkIndex(ctx, ms, opts...)
+}
+
+// BulkIndex calls BulkIndexContext with the background context
+func (c *{{.Client}}) BulkIndex(ms []*{{.ModelWithPrefix}}, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	return c.BulkIndexContext(context.Background(), ms, opts...)
+}
+
+// BulkDelete calls BulkDeleteContext with the background context
+func (c *{{.Client}}) BulkDelete(ids []{{.IDType}}, opts ...{{.Client}}BulkOption) (*{{.Client}}BulkResult, error) {
+	return c.BulkDeleteContext(context.Background(), ids, opts...)
+}
+
+// DeleteOneByID calls DeleteOneByIDContext with the background context
+func (c *{{.Client}}) DeleteOneByID(id {{.IDType}}) error {
+	return c.DeleteOneByIDContext(context.Background(), id)
+}
+
+// RecreateIndexContext deletes the index and creates it again, or points the alias of a
+// versioned index to a new empty version, see {{.Client}}WithVersionedIndex
+func (c *{{.Client}}) RecreateIndexContext(ctx context.Context) error {
+	return c.Client.RecreateIndex(ctx)
+}
+
+// RecreateIndex calls RecreateIndexContext with the background context
+func (c *{{.Client}}) RecreateIndex() error {
+	return c.RecreateIndexContext(context.Background())
+}
+
+// IndexExistsContext checks whether the index exists
+func (c *{{.Client}}) IndexExistsContext(ctx context.Context) (bool, error) {
+	return c.Client.IndexExists(ctx)
+}
+
+// IndexExists calls IndexExistsContext with the background context
+func (c *{{.Client}}) IndexExists() (bool, error) {
+	return c.IndexExistsContext(context.Background())
+}
+
+// DeleteIndexContext deletes the index, or all versions of a versioned index
+func (c *{{.Client}}) DeleteIndexContext(ctx context.Context) (bool, error) {
+	return c.Client.DeleteIndex(ctx)
+}
+
+// DeleteIndex calls DeleteIndexContext with the background context
+func (c *{{.Client}}) DeleteIndex() (bool, error) {
+	return c.DeleteIndexContext(context.Background())
+}
+
+// CreateIndexContext creates the index
+func (c *{{.Client}}) CreateIndexContext(ctx context.Context) error {
+	return c.Client.CreateIndex(ctx)
+}
+
+// CreateIndex calls CreateIndexContext with the background context
+func (c *{{.Client}}) CreateIndex() error {
+	return c.CreateIndexContext(context.Background())
+}
+
+// ReindexContext replaces the versioned index by a new version without downtime for reads, see {{.Client}}WithVersionedIndex
+// The {{.ModelWithPrefix}}s of the live version are copied, unless {{.Client}}WithReindexLoader is given
+// Writes fail with runtime.ErrUnavailable meanwhile, see runtime.Client.Reindex
+func (c *{{.Client}}) ReindexContext(ctx context.Context, opts ...{{.Client}}ReindexOption) (*{{.Client}}ReindexResult, error) {
+	return c.Client.Reindex(ctx, opts...)
+}
+
+// Reindex calls ReindexContext with the background context
+func (c *{{.Client}}) Reindex(opts ...{{.Client}}ReindexOption) (*{{.Client}}ReindexResult, error) {
+	return c.ReindexContext(context.Background(), opts...)
+}
+
+// RollbackIndexContext points the alias of the versioned index to the version before the live one
+func (c *{{.Client}}) RollbackIndexContext(ctx context.Context) error {
+	return c.Client.RollbackIndex(ctx)
+}
+
+// RollbackIndex calls RollbackIndexContext with the background context
+func (c *{{.Client}}) RollbackIndex() error {
+	return c.RollbackIndexContext(context.Background())
+}
+
+// IndexVersionsContext returns the versions of the versioned index, sorted by their version
+func (c *{{.Client}}) IndexVersionsContext(ctx context.Context) ([]{{.Client}}IndexVersion, error) {
+	return c.Client.IndexVersions(ctx)
+}
+
+// IndexVersions calls IndexVersionsContext with the background context
+func (c *{{.Client}}) IndexVersions() ([]{{.Client}}IndexVersion, error) {
+	return c.IndexVersionsContext(context.Background())
 }
 {{- end }}
 
-type {{.LowercaseClient}}DocResponse struct {
-	ID      string ` + "`" + `json:"_id"` + "`" + `
-	Result  string ` + "`" + `json:"result"` + "`" + `
-	Error	*elasticError ` + "`" + `json:"error"` + "`" + `
-}
-
-type {{.LowercaseClient}}Hits struct {
-	Took     int  ` + "`" + `json:"took"` + "`" + `
-	TimedOut bool ` + "`" + `json:"timed_out"` + "`" + `
-	Shards   struct {
-		Total      int ` + "`" + `json:"total"` + "`" + `
-		Successful int ` + "`" + `json:"successful"` + "`" + `
-		Failed     int ` + "`" + `json:"failed"` + "`" + `
-	} ` + "`" + `json:"_shards"` + "`" + `
-	Hits struct {
-		Total    int                             ` + "`" + `json:"total"` + "`" + `
-		MaxScore float64                         ` + "`" + `json:"max_score"` + "`" + `
-		Hits     []{{.LowercaseClient}}Hit ` + "`" + `json:"hits"` + "`" + `
-	} ` + "`" + `json:"hits"` + "`" + `
-	Error *elasticError ` + "`" + `json:"error"` + "`" + `
-}
-
-type {{.LowercaseClient}}Hit struct {
-	ID     string  ` + "`" + `json:"_id"` + "`" + `
-	Score  float64 ` + "`" + `json:"_score"` + "`" + `
-	Source {{.ModelWithPrefix}} ` + "`" + `json:"_source"` + "`" + `
-}`
+var {{.LowercaseClient}}IndexDefinition = ` + "`{{.IndexDefinition}}`" + `
+
+{{- if not .PreventCommonCode }}
+{{ template "common" . }}
+{{- end }}
+`
+
+// commonTemplate contains the declarations shared by all clients of a package
+var commonTemplate = `{{ define "common" }}
+// elasticError is kept for code referring to it, the errors are declared in the runtime package
+type elasticError = runtime.Error
+
+{{- end }}`
+
+var commonFileTemplate = `// Code generated by slimlastic DO NOT EDIT.
+//github.com/fvosberg/slimlastic
+
+package {{.TargetPackage}}
+
+import "github.com/fvosberg/slimlastic/runtime"
+{{ template "common" . }}
+`
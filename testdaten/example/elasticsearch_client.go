@@ -1,280 +1,624 @@
+// Code generated by slimlastic DO NOT EDIT.
+//github.com/fvosberg/slimlastic
+
 package example
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
-	"strings"
 	"time"
 
-	"github.com/fvosberg/errtypes"
-	"github.com/pkg/errors"
+	"github.com/fvosberg/slimlastic/runtime"
 )
 
-// NewExampleElasticsearchClient instantiates a new elasticsearch client
-// which is dedicated to the struct example.Example
-func NewExampleElasticsearchClient(url string) (*exampleElasticsearchClient, error) {
-	url = strings.TrimRight(url, "/")
-	c := &exampleElasticsearchClient{
-		http:     &http.Client{Timeout: 1 * time.Second},
-		indexURL: fmt.Sprintf("%s/examples", url),
-		typeURL:  fmt.Sprintf("%s/examples/example", url),
-	}
-	indexExists, err := c.IndexExists()
-	if err != nil {
-		return nil, err
-	}
-	if indexExists {
+// newExampleElasticsearchClient instantiates a new elasticsearch client
+// which is dedicated to the struct github.com/fvosberg/slimlastic/testdaten/example.Example
+func newExampleElasticsearchClient(ctx context.Context, url string, opts ...exampleElasticsearchClientOption) (*exampleElasticsearchClient, error) {
+	c := &exampleElasticsearchClient{}
+	c.Init(url)
+	for _, o := range opts {
+		o(c)
+	}
+	if c.skipIndexCreation {
 		return c, nil
 	}
-	err = c.CreateIndex()
+	err := c.Client.EnsureExistingIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// exampleElasticsearchClient is the elasticsearch client for Example
+// The methods without a typed ID are provided by the embedded runtime.Client
 type exampleElasticsearchClient struct {
-	http     *http.Client
-	indexURL string
-	typeURL  string
+	*runtime.Client[Example]
+	skipIndexCreation bool
 }
 
-func (c *exampleElasticsearchClient) Refresh() error {
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/_refresh", c.indexURL), nil)
-	if err != nil {
-		return errors.Wrap(err, "creating refresh request failed")
-	}
-	res, err := c.http.Do(req)
-	if err != nil {
-		return errors.Wrap(err, "refreshing examples index failed")
-	}
-	defer res.Body.Close()
-	var result struct {
-		Shards struct {
-			Total      int `json:"total"`
-			Successful int `json:"successful"`
-			Failed     int `json:"failed"`
-		} `json:"_shards"`
-	}
-	err = json.NewDecoder(res.Body).Decode(&result)
-	if err != nil {
-		return errors.Wrap(err, "decoding of refresh response failed")
-	}
-	if result.Shards.Failed != 0 {
-		return fmt.Errorf("Refreshing of %d shards failed (%d successful; %d in total)", result.Shards.Failed, result.Shards.Successful, result.Shards.Total)
-	}
-	return nil
+// Init initializes the client for the elasticsearch nodes at url, which can contain
+// multiple URLs separated by commas, without sending any request
+func (c *exampleElasticsearchClient) Init(url string) {
+	c.Client = runtime.NewClient(url, exampleElasticsearchClientModel, 2*time.Second)
 }
 
-func (c *exampleElasticsearchClient) GetOneByID(ID string) (*Example, error) {
-	res, err := ds.http.Get(fmt.Sprintf("%s/%s", ds.typeURL, ID))
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-	var response struct {
-		ID     string      `json:"_id"`
-		Source Transaction `json:"_source"`
-		Found  bool        `json:"found"`
-	}
-	err = json.NewDecoder(res.Body).Decode(&response)
-	if err != nil {
-		return nil, errors.Wrap(err, "decoding of by ID response failed")
-	}
-	if !response.Found {
-		return nil, errtypes.NewNotFoundf("transaction with id %d not found", ID)
+// exampleElasticsearchClientOption configures the exampleElasticsearchClient on construction
+type exampleElasticsearchClientOption func(*exampleElasticsearchClient)
+
+// exampleElasticsearchClientWithTimeout overrides the default timeout of 2 * time.Second for requests to elasticsearch
+func exampleElasticsearchClientWithTimeout(d time.Duration) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetTimeout(d)
 	}
-	response.Source.ID = response.ID
-	return &response.Source, nil
 }
 
-func exampleFromElasticsearchHit(hit exampleElasticsearchClientHit) Example {
-	hit.Example.ID = hit.ID
-	return hit.Example
+// exampleElasticsearchClientWithHTTPClient sends the requests with a copy of h, to share its connections with other clients
+// The timeout of the client is kept, when h has none. TLS options like exampleElasticsearchClientWithCACertFile are
+// applied to a clone of its transport regardless of the order of the options, see exampleElasticsearchClientWithTransport
+func exampleElasticsearchClientWithHTTPClient(h *http.Client) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetHTTPClient(h)
+	}
 }
 
-func examplesFromElasticsearchHits(hits []exampleElasticsearchClientHits) []Example {
-	res := make([]Example, len(hits))
-	for n, h := range hits {
-		res[n] = exampleFromElasticsearchHit(h)
+// exampleElasticsearchClientWithTransport sends the requests with the transport, like one which adds tracing
+// TLS options like exampleElasticsearchClientWithCACertFile are applied to a clone of it regardless of the order of
+// the options. The requests fail, when TLS options are combined with a transport other than *http.Transport
+func exampleElasticsearchClientWithTransport(t http.RoundTripper) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetTransport(t)
 	}
-	return res
 }
 
-func (c *exampleElasticsearchClient) GetList(offset, limit int) ([]Example, error) {
-	res, err := c.http.Get(fmt.Sprintf("%s/_search?size=%d&from=%d", ds.typeURL, limit, offset))
-	if err != nil {
-		return nil, err
+// exampleElasticsearchClientWithIndexName overrides the index name examples
+func exampleElasticsearchClientWithIndexName(name string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetIndex(name)
 	}
-	defer res.Body.Close()
-	var result exampleElasticsearchClientHits
-	err = json.NewDecoder(res.Body).Decode(&result)
-	if err != nil {
-		return nil, errors.Wrap(err, "decoding of search response failed")
+}
+
+// exampleElasticsearchClientWithoutIndexCreation skips the creation of the index by newExampleElasticsearchClient, which then sends no request
+func exampleElasticsearchClientWithoutIndexCreation() exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.skipIndexCreation = true
 	}
-	return examplesFromElasticsearchHits(result.Hits.Hits), nil
 }
 
-// Index creates a new Example in elasticsearch
-// When the ID of the example is set, it updates the example
-// The first return value indicates, whether a new records has been created or not
-func (c *exampleElasticsearchClient) Index(m *Example) (bool, error) {
-	body := &bytes.Buffer{}
-	err := json.NewEncoder(body).Encode(t)
-	if err != nil {
-		return false, errors.Wrap(err, "JSON encoding of example failed")
+// exampleElasticsearchClientWithVersionedIndex uses the index name as alias of versioned indices like examples_v1, which are
+// replaced by Reindex without downtime for reads. newExampleElasticsearchClient creates the first version, if the alias doesn't exist
+func exampleElasticsearchClientWithVersionedIndex() exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetVersioned()
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s", c.typeURL, m.ID), body)
-	if err != nil {
-		return false, errors.Wrap(err, "creating index request failed")
+}
+
+// exampleElasticsearchClientWithNodes spreads the requests round-robin over the elasticsearch nodes at the urls
+// Invalid urls are reported by the requests
+func exampleElasticsearchClientWithNodes(urls ...string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		_ = c.SetNodes(urls...)
 	}
-	res, err := c.http.Do(req)
-	if err != nil {
-		return false, errors.Wrap(err, "request to elasticsearch failed")
+}
+
+// exampleElasticsearchClientWithResurrectTimeout skips a node for d after a connection error, doubled for every consecutive one
+func exampleElasticsearchClientWithResurrectTimeout(d time.Duration) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetResurrectTimeout(d)
 	}
-	defer res.Body.Close()
-	var response exampleElasticsearchClientIndexResponse
-	err = json.NewDecoder(res.Body).Decode(&response)
-	if err != nil {
-		return false, errors.Wrap(err, "decoding of elasticsearch response failed")
+}
+
+// exampleElasticsearchClientWithSniffInterval discovers the nodes of the cluster, when the last discovery is older than d
+func exampleElasticsearchClientWithSniffInterval(d time.Duration) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetSniffInterval(d)
 	}
-	if response.ID == "" || (response.Result != "updated" && !response.Created) {
-		// if this case happens, please report with furhter information to hello@frederikvosberg.de to implement a better error handling
-		return false, errors.New("indexing of document in elasticsearch failed")
+}
+
+// exampleElasticsearchClientWithBasicAuth authenticates the requests with basic auth
+func exampleElasticsearchClientWithBasicAuth(user, password string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetBasicAuth(user, password)
 	}
-	t.ID = response.ID
-	return response.Created, nil
 }
 
-func (c *exampleElasticsearchClient) RecreateIndex() error {
-	err := ds.DeleteIndex()
-	if err != nil {
-		return err
+// exampleElasticsearchClientWithAPIKey authenticates the requests with the encoded API key
+func exampleElasticsearchClientWithAPIKey(key string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetAPIKey(key)
 	}
-	return ds.CreateIndex()
 }
 
-func (c *exampleElasticsearchClient) IndexExists() (bool, error) {
-	req, err := http.NewRequest("HEAD", c.indexURL, nil)
-	if err != nil {
-		return false, err
+// exampleElasticsearchClientWithBearerToken authenticates the requests with the bearer token
+func exampleElasticsearchClientWithBearerToken(token string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetBearerToken(token)
 	}
-	res, err := c.http.Do(req)
-	if err != nil {
-		return false, err
+}
+
+// exampleElasticsearchClientWithCACertFile trusts only the certificate authorities of the PEM file
+// Errors are reported by the requests
+func exampleElasticsearchClientWithCACertFile(path string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		_ = c.SetCACertFile(path)
 	}
-	res.Body.Close()
-	if res.StatusCode != 200 && res.StatusCode != 404 {
-		return false, fmt.Errorf("status code should be 200 or 404 on checking existence of \"example\" index, but was %d", res.StatusCode)
+}
+
+// exampleElasticsearchClientWithClientCertFiles authenticates the client with the certificate and key of the PEM files
+// Errors are reported by the requests
+func exampleElasticsearchClientWithClientCertFiles(certFile, keyFile string) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		_ = c.SetClientCertFiles(certFile, keyFile)
 	}
-	return res.StatusCode == 200, nil
 }
 
-func (c *exampleElasticsearchClient) DeleteIndex() error {
-	req, err := http.NewRequest("DELETE", c.indexURL, nil)
-	if err != nil {
-		return err
+// exampleElasticsearchClientWithInsecureSkipVerify disables the verification of the certificates of the nodes, only for development
+func exampleElasticsearchClientWithInsecureSkipVerify() exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		_ = c.SetInsecureSkipVerify(true)
 	}
-	res, err := ds.http.Do(req)
-	if err != nil {
-		return err
+}
+
+// exampleElasticsearchClientWithMiddleware wraps the sending of every request with the middlewares, like runtime.Logging
+func exampleElasticsearchClientWithMiddleware(mw ...runtime.Middleware) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.Use(mw...)
 	}
-	defer res.Body.Close()
-	var response exampleElasticsearchClientIndexResponse
-	err = json.NewDecoder(res.Body).Decode(&response)
-	if err != nil {
-		return errors.Wrap(err, "couldn't decode JSON response")
+}
+
+// exampleElasticsearchClientWithInstrumentation reports every operation of the client to the instrumentations,
+// like those of the runtime/prommetrics and runtime/oteltrace packages
+func exampleElasticsearchClientWithInstrumentation(in ...runtime.Instrumentation) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.Instrument(in...)
 	}
-	if !response.Acknowledged {
-		return fmt.Errorf("deletion of index not acknowledged: %#v", response.Error)
+}
+
+// exampleElasticsearchClientWithRetryPolicy retries failed requests according to the policy, see runtime.DefaultRetryPolicy
+func exampleElasticsearchClientWithRetryPolicy(p runtime.RetryPolicy) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetRetryPolicy(p)
 	}
-	return nil
 }
 
-func (c *exampleElasticsearchClient) CreateIndex() error {
-	req, err := http.NewRequest("PUT", c.indexURL, strings.NewReader(exampleElasticsearchClientIndexDefinition))
-	if err != nil {
-		return err
+// WithTimeout returns a copy of the client, which uses the given timeout for its requests
+// It's meant for single calls which need a different deadline, like
+// c.WithTimeout(10 * time.Minute).RecreateIndex(ctx)
+func (c *exampleElasticsearchClient) WithTimeout(d time.Duration) *exampleElasticsearchClient {
+	return &exampleElasticsearchClient{Client: c.Client.WithTimeout(d), skipIndexCreation: c.skipIndexCreation}
+}
+
+// GetOneByID returns the Example with the given ID
+func (c *exampleElasticsearchClient) GetOneByID(ctx context.Context, id string) (*Example, error) {
+	return c.Client.GetOneByID(ctx, exampleElasticsearchClientIDToString(id))
+}
+
+// DeleteOneByID deletes a Example in elasticsearch, given its ID
+func (c *exampleElasticsearchClient) DeleteOneByID(ctx context.Context, id string) error {
+	return c.Client.DeleteOneByID(ctx, exampleElasticsearchClientIDToString(id))
+}
+
+// exampleElasticsearchClientHit is a Example found by Search, with its score
+type exampleElasticsearchClientHit = runtime.Hit[Example]
+
+type exampleElasticsearchClientListRequestOptions = runtime.ListOptions
+
+type exampleElasticsearchClientListRequestOpt = runtime.ListOption
+
+func exampleElasticsearchClientWithTotal(t *uint32) exampleElasticsearchClientListRequestOpt {
+	return runtime.WithTotal(t)
+}
+
+type exampleElasticsearchClientIndexOption = runtime.IndexOption
+
+type exampleElasticsearchClientIndexConfig = runtime.IndexConfig
+
+// exampleElasticsearchClientForceIndexRefresh forces the immediate refresh after indexing
+// it's used as an exampleElasticsearchClientIndexOption param to exampleElasticsearchClient.Index
+func exampleElasticsearchClientForceIndexRefresh(cfg *exampleElasticsearchClientIndexConfig) {
+	runtime.ForceRefresh(cfg)
+}
+
+// Deprecated: use exampleElasticsearchClientIndexOption
+type exampleElasticsearchIndexOption = runtime.IndexOption
+
+// Deprecated: use exampleElasticsearchClientIndexConfig
+type exampleElasticsearchIndexConfig = runtime.IndexConfig
+
+// ForceExampleIndexRefresh forces the immediate refresh after indexing
+//
+// Deprecated: use exampleElasticsearchClientForceIndexRefresh
+func ForceExampleIndexRefresh(cfg *exampleElasticsearchIndexConfig) {
+	runtime.ForceRefresh(cfg)
+}
+
+// BulkDelete deletes the Examples with the given IDs with the _bulk API
+// The failed deletions are reported by the result, see exampleElasticsearchClientBulkResult.Err
+func (c *exampleElasticsearchClient) BulkDelete(ctx context.Context, ids []string, opts ...exampleElasticsearchClientBulkOption) (*exampleElasticsearchClientBulkResult, error) {
+	strIDs := make([]string, len(ids))
+	for n, id := range ids {
+		strIDs[n] = exampleElasticsearchClientIDToString(id)
+	}
+	return c.Client.BulkDelete(ctx, strIDs, opts...)
+}
+
+// exampleElasticsearchClientBulkOp is an operation of Bulk, see exampleElasticsearchClientIndexOp and exampleElasticsearchClientDeleteOp
+type exampleElasticsearchClientBulkOp = runtime.BulkOp[Example]
+
+// exampleElasticsearchClientIndexOp creates or updates the Example in Bulk
+func exampleElasticsearchClientIndexOp(m *Example) exampleElasticsearchClientBulkOp {
+	return runtime.IndexOp(m)
+}
+
+// exampleElasticsearchClientDeleteOp deletes the Example with the given ID in Bulk
+func exampleElasticsearchClientDeleteOp(id string) exampleElasticsearchClientBulkOp {
+	return runtime.DeleteOp[Example](exampleElasticsearchClientIDToString(id))
+}
+
+type exampleElasticsearchClientBulkOption = runtime.BulkOption
+
+type exampleElasticsearchClientBulkResult = runtime.BulkResult
+
+// exampleElasticsearchClientWithBulkMaxDocs splits bulk operations into requests of at most n operations
+func exampleElasticsearchClientWithBulkMaxDocs(n int) exampleElasticsearchClientBulkOption {
+	return runtime.WithBulkMaxDocs(n)
+}
+
+// exampleElasticsearchClientWithBulkMaxBytes splits bulk operations into requests with bodies of at most n bytes
+func exampleElasticsearchClientWithBulkMaxBytes(n int) exampleElasticsearchClientBulkOption {
+	return runtime.WithBulkMaxBytes(n)
+}
+
+// exampleElasticsearchClientForceBulkRefresh forces the immediate refresh after each bulk request
+func exampleElasticsearchClientForceBulkRefresh(cfg *runtime.BulkConfig) {
+	runtime.ForceBulkRefresh(cfg)
+}
+
+// exampleElasticsearchClientBulkProcessor indexes and deletes Examples in the background, see
+// exampleElasticsearchClient.NewBulkProcessor. Deletions by ID are added with exampleElasticsearchClientDeleteOp
+type exampleElasticsearchClientBulkProcessor = runtime.BulkProcessor[Example]
+
+type exampleElasticsearchClientBulkProcessorOption = runtime.BulkProcessorOption[Example]
+
+// exampleElasticsearchClientWithBulkWorkers sets the number of concurrent bulk requests of the exampleElasticsearchClientBulkProcessor
+func exampleElasticsearchClientWithBulkWorkers(n int) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.WithBulkWorkers[Example](n)
+}
+
+// exampleElasticsearchClientWithFlushInterval flushes the buffered operations of the exampleElasticsearchClientBulkProcessor at least every d
+func exampleElasticsearchClientWithFlushInterval(d time.Duration) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.WithFlushInterval[Example](d)
+}
+
+// exampleElasticsearchClientWithFlushDocs flushes the buffered operations of the exampleElasticsearchClientBulkProcessor, when there are n of them
+func exampleElasticsearchClientWithFlushDocs(n int) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.WithFlushDocs[Example](n)
+}
+
+// exampleElasticsearchClientWithFlushBytes flushes the buffered operations of the exampleElasticsearchClientBulkProcessor, when they reach n bytes
+func exampleElasticsearchClientWithFlushBytes(n int) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.WithFlushBytes[Example](n)
+}
+
+// exampleElasticsearchClientWithBulkRetries retries failed operations of the exampleElasticsearchClientBulkProcessor n times with an exponential backoff
+func exampleElasticsearchClientWithBulkRetries(n int, backoff, maxBackoff time.Duration) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.WithBulkRetries[Example](n, backoff, maxBackoff)
+}
+
+// exampleElasticsearchClientOnBulkFailure reports the operations of the exampleElasticsearchClientBulkProcessor, which failed finally, to f
+func exampleElasticsearchClientOnBulkFailure(f func([]exampleElasticsearchClientBulkOp, error)) exampleElasticsearchClientBulkProcessorOption {
+	return runtime.OnBulkFailure(f)
+}
+
+// Find returns the Examples matching the query
+func (c *exampleElasticsearchClient) Find(ctx context.Context, q *exampleElasticsearchClientQueryBuilder, opts ...exampleElasticsearchClientListRequestOpt) ([]Example, error) {
+	return c.Client.Find(ctx, q.Query(), opts...)
+}
+
+// GetPage returns size Examples matching the query, starting after the Example the
+// cursor points to, or at the first one for an empty cursor. The Examples are sorted by the
+// sorts of the query and their ID as tiebreaker. The cursor of the next page is signed, see exampleElasticsearchClientWithCursorKey
+func (c *exampleElasticsearchClient) GetPage(ctx context.Context, q *exampleElasticsearchClientQueryBuilder, cursor string, size int) (exampleElasticsearchClientPage, error) {
+	return c.Client.GetPage(ctx, q.Query(), cursor, size)
+}
+
+// exampleElasticsearchClientPage is a page of Examples returned by GetPage
+type exampleElasticsearchClientPage = runtime.Page[Example]
+
+// exampleElasticsearchClientWithCursorKey sets the key, which signs the cursors of GetPage
+// Without it, the cursors are only valid for the running process
+func exampleElasticsearchClientWithCursorKey(key []byte) exampleElasticsearchClientOption {
+	return func(c *exampleElasticsearchClient) {
+		c.SetCursorKey(key)
 	}
-	res, err := ds.http.Do(req)
-	if err != nil {
-		return err
+}
+
+// All returns an iterator over all Examples matching the query, using the scroll API
+// A nil query matches all Examples. The iterator has to be closed, when it's not iterated until the end
+func (c *exampleElasticsearchClient) All(ctx context.Context, q *exampleElasticsearchClientQueryBuilder, opts ...exampleElasticsearchClientScrollOption) *exampleElasticsearchClientIterator {
+	return c.Client.All(ctx, q.Query(), opts...)
+}
+
+// Each calls f for every Example matching the query, until f returns an error, see All
+func (c *exampleElasticsearchClient) Each(ctx context.Context, q *exampleElasticsearchClientQueryBuilder, f func(*Example) error, opts ...exampleElasticsearchClientScrollOption) error {
+	return c.Client.Each(ctx, q.Query(), f, opts...)
+}
+
+// exampleElasticsearchClientIterator iterates over Examples, see All
+type exampleElasticsearchClientIterator = runtime.Iterator[Example]
+
+type exampleElasticsearchClientScrollOption = runtime.ScrollOption
+
+// exampleElasticsearchClientWithScrollSize fetches n Examples per request of All and Each
+func exampleElasticsearchClientWithScrollSize(n int) exampleElasticsearchClientScrollOption {
+	return runtime.WithScrollSize(n)
+}
+
+// exampleElasticsearchClientWithScrollKeepAlive keeps the scroll context of All and Each alive for d between two requests
+func exampleElasticsearchClientWithScrollKeepAlive(d time.Duration) exampleElasticsearchClientScrollOption {
+	return runtime.WithScrollKeepAlive(d)
+}
+
+type exampleElasticsearchClientReindexOption = runtime.ReindexOption[Example]
+
+type exampleElasticsearchClientReindexResult = runtime.ReindexResult
+
+// exampleElasticsearchClientIndexVersion is an index version behind the alias, see exampleElasticsearchClientWithVersionedIndex
+type exampleElasticsearchClientIndexVersion = runtime.IndexVersion
+
+// exampleElasticsearchClientWithKeepIndexVersions keeps n old index versions for RollbackIndex, older ones are deleted by Reindex
+func exampleElasticsearchClientWithKeepIndexVersions(n int) exampleElasticsearchClientReindexOption {
+	return runtime.WithKeepIndexVersions[Example](n)
+}
+
+// exampleElasticsearchClientWithReindexLoader fills the new index version of Reindex with f instead of copying the
+// Examples of the live one. The client passed to f addresses the new index version
+func exampleElasticsearchClientWithReindexLoader(f func(ctx context.Context, c *exampleElasticsearchClient) error) exampleElasticsearchClientReindexOption {
+	return runtime.WithReindexLoader(func(ctx context.Context, c *runtime.Client[Example]) error {
+		return f(ctx, &exampleElasticsearchClient{Client: c, skipIndexCreation: true})
+	})
+}
+
+// exampleElasticsearchClientField is the path of a field of Example in elasticsearch
+type exampleElasticsearchClientField string
+
+// paths of the fields of Example, including those of nested structs
+const (
+	exampleElasticsearchClientFieldFoo        exampleElasticsearchClientField = "foo"
+	exampleElasticsearchClientFieldBar        exampleElasticsearchClientField = "bar"
+	exampleElasticsearchClientFieldText       exampleElasticsearchClientField = "text"
+	exampleElasticsearchClientFieldCreated    exampleElasticsearchClientField = "created"
+	exampleElasticsearchClientFieldTags       exampleElasticsearchClientField = "tags"
+	exampleElasticsearchClientFieldEntity     exampleElasticsearchClientField = "entity"
+	exampleElasticsearchClientFieldEntityID   exampleElasticsearchClientField = "entity.id"
+	exampleElasticsearchClientFieldEntityType exampleElasticsearchClientField = "entity.type"
+	exampleElasticsearchClientFieldEntityName exampleElasticsearchClientField = "entity.name"
+)
+
+// String returns the path of the field
+func (f exampleElasticsearchClientField) String() string {
+	return string(f)
+}
+
+// NestedPath returns the path of the nested document containing the field, which
+// has to be queried by a nested query, or an empty string, if there is none
+func (f exampleElasticsearchClientField) NestedPath() exampleElasticsearchClientField {
+	switch f {
+	case exampleElasticsearchClientFieldEntityID:
+		return exampleElasticsearchClientFieldEntity
+	case exampleElasticsearchClientFieldEntityType:
+		return exampleElasticsearchClientFieldEntity
+	case exampleElasticsearchClientFieldEntityName:
+		return exampleElasticsearchClientFieldEntity
+	}
+	return ""
+}
+
+// exampleElasticsearchClientQueryBuilder builds a query for Examples, see exampleElasticsearchClient.Find
+type exampleElasticsearchClientQueryBuilder struct {
+	q runtime.Query
+}
+
+// exampleElasticsearchClientQuery starts a new query for Examples
+func exampleElasticsearchClientQuery() *exampleElasticsearchClientQueryBuilder {
+	return &exampleElasticsearchClientQueryBuilder{}
+}
+
+// Query returns the built query, which can be encoded to JSON for DoListRequest
+// A nil builder returns a nil query, which matches all Examples
+func (b *exampleElasticsearchClientQueryBuilder) Query() *runtime.Query {
+	if b == nil {
+		return nil
 	}
-	defer res.Body.Close()
-	var response exampleElasticsearchClientIndexResponse
-	err = json.NewDecoder(res.Body).Decode(&response)
-	if err != nil {
-		return errors.Wrap(err, "couldn't decode JSON response")
+	return &b.q
+}
+
+// SortBy sorts the Examples by the field in the order runtime.Asc or runtime.Desc, sorts are applied in the order they are added
+func (b *exampleElasticsearchClientQueryBuilder) SortBy(f exampleElasticsearchClientField, o runtime.SortOrder) *exampleElasticsearchClientQueryBuilder {
+	b.q.Sort(string(f), o)
+	return b
+}
+
+// From sets the offset of the first Example
+func (b *exampleElasticsearchClientQueryBuilder) From(n int) *exampleElasticsearchClientQueryBuilder {
+	b.q.From(n)
+	return b
+}
+
+// Size sets the maximum number of Examples
+func (b *exampleElasticsearchClientQueryBuilder) Size(n int) *exampleElasticsearchClientQueryBuilder {
+	b.q.Size(n)
+	return b
+}
+
+func (b *exampleElasticsearchClientQueryBuilder) add(nested string, must bool, c runtime.Clause) *exampleElasticsearchClientQueryBuilder {
+	if nested != "" {
+		c = runtime.Nested(nested, c)
 	}
-	if !response.Acknowledged {
-		return fmt.Errorf("creation of index not acknowledged: %#v", response.Error)
+	if must {
+		b.q.Must(c)
+	} else {
+		b.q.Filter(c)
 	}
-	return nil
+	return b
 }
 
-var exampleElasticsearchClientIndexDefinition = `{
-    "settings" : {
-        "number_of_shards" : 1
-    },
-    "mappings" : {
-        "transaction" : {
-            "properties" : {
-                "entity" : {
-					"type" : "nested",
-					"properties": {
-						"id": {"type": "keyword"},
-						"type": {"type": "keyword"}
-					}
-				},
-				"status": {"type": "keyword"},
-				"status_reason": {"type": "keyword"},
-				"partner": {"type": "keyword"},
-				"customer": {"type": "keyword"},
-				"created": {"type": "date"},
-				"points": {"type": "integer"}
-            }
-        }
-    }
-}`
+// FooEquals matches Examples, whose foo is v
+func (b *exampleElasticsearchClientQueryBuilder) FooEquals(v string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Term("foo", v))
+}
+
+// FooIn matches Examples, whose foo is one of the values
+func (b *exampleElasticsearchClientQueryBuilder) FooIn(v ...string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Terms("foo", v...))
+}
+
+// BarEquals matches Examples, whose bar is v
+func (b *exampleElasticsearchClientQueryBuilder) BarEquals(v int) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Term("bar", v))
+}
+
+// BarBetween matches Examples, whose bar is between from and to, both inclusive
+func (b *exampleElasticsearchClientQueryBuilder) BarBetween(from, to int) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Between("bar", from, to))
+}
+
+// BarGreaterThan matches Examples, whose bar is greater than v
+func (b *exampleElasticsearchClientQueryBuilder) BarGreaterThan(v int) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Range("bar", runtime.Gt, v))
+}
+
+// BarLessThan matches Examples, whose bar is less than v
+func (b *exampleElasticsearchClientQueryBuilder) BarLessThan(v int) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Range("bar", runtime.Lt, v))
+}
+
+// TextMatches matches Examples, whose text matches the text
+func (b *exampleElasticsearchClientQueryBuilder) TextMatches(txt string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", true, runtime.Match("text", txt))
+}
+
+// CreatedEquals matches Examples, whose created is v
+func (b *exampleElasticsearchClientQueryBuilder) CreatedEquals(v time.Time) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Term("created", v))
+}
+
+// CreatedBetween matches Examples, whose created is between from and to, both inclusive
+func (b *exampleElasticsearchClientQueryBuilder) CreatedBetween(from, to time.Time) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Between("created", from, to))
+}
+
+// CreatedGreaterThan matches Examples, whose created is greater than v
+func (b *exampleElasticsearchClientQueryBuilder) CreatedGreaterThan(v time.Time) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Range("created", runtime.Gt, v))
+}
+
+// CreatedLessThan matches Examples, whose created is less than v
+func (b *exampleElasticsearchClientQueryBuilder) CreatedLessThan(v time.Time) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Range("created", runtime.Lt, v))
+}
+
+// TagsEquals matches Examples, whose tags is v
+func (b *exampleElasticsearchClientQueryBuilder) TagsEquals(v string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Term("tags", v))
+}
+
+// TagsIn matches Examples, whose tags is one of the values
+func (b *exampleElasticsearchClientQueryBuilder) TagsIn(v ...string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("", false, runtime.Terms("tags", v...))
+}
 
-type exampleElasticsearchClientIndexManipulationResponse struct {
-	Acknowledged bool         `json:"acknowledged"`
-	Status       int          `json:"status"`
-	Error        elasticError `json:"error"`
+// EntityIDEquals matches Examples, whose entity.id is v
+func (b *exampleElasticsearchClientQueryBuilder) EntityIDEquals(v string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("entity", false, runtime.Term("entity.id", v))
 }
 
-type exampleElasticsearchClientError struct {
-	Type      string         `json:"type"`
-	Reason    string         `json:"reason"`
-	CausedBy  *elasticError  `json:"caused_by"`
-	RootCause []elasticError `json:"root_cause"`
+// EntityIDIn matches Examples, whose entity.id is one of the values
+func (b *exampleElasticsearchClientQueryBuilder) EntityIDIn(v ...string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("entity", false, runtime.Terms("entity.id", v...))
 }
 
-type exampleElasticsearchClientIndexExampleResponse struct {
-	ID      string `json:"_id"`
-	Created bool   `json:"created"`
-	Result  string `json:"result"`
+// EntityTypeEquals matches Examples, whose entity.type is v
+func (b *exampleElasticsearchClientQueryBuilder) EntityTypeEquals(v string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("entity", false, runtime.Term("entity.type", v))
 }
 
-type exampleElasticsearchClientHits struct {
-	Took     int  `json:"took"`
-	TimedOut bool `json:"timed_out"`
-	Shards   struct {
-		Total      int `json:"total"`
-		Successful int `json:"successful"`
-		Failed     int `json:"failed"`
-	} `json:"_shards"`
-	Hits struct {
-		Total    int                             `json:"total"`
-		MaxScore float64                         `json:"max_score"`
-		Hits     []exampleElasticsearchClientHit `json:"hits"`
-	} `json:"hits"`
+// EntityTypeIn matches Examples, whose entity.type is one of the values
+func (b *exampleElasticsearchClientQueryBuilder) EntityTypeIn(v ...string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("entity", false, runtime.Terms("entity.type", v...))
 }
 
-type exampleElasticsearchClientHit struct {
-	ID     string  `json:"_id"`
-	Score  float64 `json:"_score"`
-	Source Example `json:"_source"`
+// EntityNameMatches matches Examples, whose entity.name matches the text
+func (b *exampleElasticsearchClientQueryBuilder) EntityNameMatches(txt string) *exampleElasticsearchClientQueryBuilder {
+	return b.add("entity", true, runtime.Match("entity.name", txt))
 }
+
+// exampleElasticsearchClientIDToString converts the ID of a Example to the elasticsearch _id
+// The zero value is converted to an empty string, to let elasticsearch generate an ID
+func exampleElasticsearchClientIDToString(id string) string {
+	return string(id)
+}
+
+// exampleElasticsearchClientIDFromString converts the elasticsearch _id to the ID of a Example
+func exampleElasticsearchClientIDFromString(s string) (string, error) {
+	return string(s), nil
+}
+
+var exampleElasticsearchClientModel = runtime.Model[Example]{
+	Index:           "examples",
+	Type:            "example",
+	IndexDefinition: exampleElasticsearchClientIndexDefinition,
+	SearchFields:    []string{"text^2"},
+	IDPath:          "id",
+	StoreID:         true,
+	ID: func(m *Example) string {
+		return exampleElasticsearchClientIDToString(m.ID)
+	},
+	SetID: func(m *Example, id string) error {
+		var err error
+		m.ID, err = exampleElasticsearchClientIDFromString(id)
+		return err
+	},
+	GeneratedIDs: true,
+}
+
+var exampleElasticsearchClientIndexDefinition = `{
+	"mappings": {
+		"example": {
+			"properties": {
+				"bar": {
+					"type": "long"
+				},
+				"created": {
+					"type": "date"
+				},
+				"entity": {
+					"properties": {
+						"id": {
+							"type": "keyword"
+						},
+						"name": {
+							"type": "text"
+						},
+						"type": {
+							"type": "keyword"
+						}
+					},
+					"type": "nested"
+				},
+				"foo": {
+					"type": "keyword"
+				},
+				"id": {
+					"type": "keyword"
+				},
+				"tags": {
+					"type": "keyword"
+				},
+				"text": {
+					"analyzer": "german",
+					"type": "text"
+				}
+			}
+		}
+	}
+}`
@@ -1,9 +1,30 @@
 package example
 
-//go:generate go run ../../cmds/generate/main.go -out elasticsearch_client.go -indexDefinition example.json Example
+import "time"
+
+//go:generate go run ../../cmds/slimlastic generate -config slimlastic.yaml
 
 // Example is an example struct. It's just testdata for the generation of the client
 type Example struct {
-	Foo string `json:"foo"`
-	Bar int    `json:"bar"`
+	ID      string    `json:"-"`
+	Foo     string    `json:"foo"`
+	Bar     int       `json:"bar"`
+	Text    string    `json:"text" es:"text,analyzer=german,searchBoost=2"`
+	Created time.Time `json:"created"`
+	Tags    []string  `json:"tags"`
+	Entity  Entity    `json:"entity" es:"nested"`
+}
+
+// Entity is an example for a nested struct
+type Entity struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name" es:"text"`
+}
+
+// Note is an example for a second model in the same package, with an integer ID
+type Note struct {
+	Number  int64  `json:"-" es:"id"`
+	Text    string `json:"text" es:"text"`
+	Example string `json:"example"`
 }